@@ -10,6 +10,8 @@ type Action struct {
 	Key []byte
 	// Value of block
 	Value []byte
+	// Metadata of block, optional
+	Metadata Metadata
 }
 
 // MarshalEnkodo is a enkodo encoding helper func
@@ -29,7 +31,13 @@ func (a *Action) MarshalEnkodo(enc *enkodo.Encoder) (err error) {
 		return
 	}
 
-	return
+	if a.Metadata.IsEmpty() {
+		// Omit empty metadata, the encoding matches actions written before metadata existed
+		return
+	}
+
+	// Write metadata
+	return enc.Encode(&a.Metadata)
 }
 
 // UnmarshalEnkodo is a enkodo decoding helper func
@@ -54,5 +62,6 @@ func (a *Action) UnmarshalEnkodo(dec *enkodo.Decoder) (err error) {
 		return
 	}
 
-	return
+	// Decode metadata, will remain empty when the action has no metadata
+	return dec.Decode(&a.Metadata)
 }
@@ -0,0 +1,40 @@
+package action
+
+import (
+	"bytes"
+
+	"github.com/mojura/enkodo"
+)
+
+// Lookup represents a lookup set or removed for an entry
+type Lookup struct {
+	// Key of lookup (e.g. "email")
+	Key string
+	// ID of lookup (e.g. "user@example.com")
+	ID string
+}
+
+// MarshalEnkodo is a enkodo encoding helper func
+func (l *Lookup) MarshalEnkodo(enc *enkodo.Encoder) (err error) {
+	if err = enc.String(l.Key); err != nil {
+		return
+	}
+
+	return enc.String(l.ID)
+}
+
+// UnmarshalEnkodo is a enkodo decoding helper func
+func (l *Lookup) UnmarshalEnkodo(dec *enkodo.Decoder) (err error) {
+	if l.Key, err = dec.String(); err != nil {
+		return
+	}
+
+	l.ID, err = dec.String()
+	return
+}
+
+// Lookup will decode the lookup of a lookup action
+func (a *Action) Lookup() (l Lookup, err error) {
+	err = enkodo.NewReader(bytes.NewReader(a.Value)).Decode(&l)
+	return
+}
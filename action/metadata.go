@@ -0,0 +1,109 @@
+package action
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mojura/enkodo"
+)
+
+// metadataVersion is the version of the metadata encoding. Metadata is appended after the
+// value of an action, so actions written before metadata existed decode with empty metadata
+const metadataVersion uint8 = 2
+
+// Metadata represents information about the writer of an action
+type Metadata struct {
+	// ActorID is the ID of the actor who performed the action
+	ActorID string
+	// RequestID is the ID of the request which performed the action
+	RequestID string
+	// Reason is a description of why the action was performed
+	Reason string
+	// Timestamp is the wall-clock time of the action
+	Timestamp time.Time
+	// Position is the change feed position of the action, zero when the action is not a change.
+	// Move actions represent two changes, the second change follows the position
+	Position uint64
+}
+
+// IsEmpty will return whether or not the metadata has any values set
+func (m *Metadata) IsEmpty() bool {
+	return m.ActorID == "" && m.RequestID == "" && m.Reason == "" && m.Timestamp.IsZero() && m.Position == 0
+}
+
+// MarshalEnkodo is a enkodo encoding helper func
+func (m *Metadata) MarshalEnkodo(enc *enkodo.Encoder) (err error) {
+	// Write version as uint8
+	if err = enc.Uint8(metadataVersion); err != nil {
+		return
+	}
+
+	if err = enc.String(m.ActorID); err != nil {
+		return
+	}
+
+	if err = enc.String(m.RequestID); err != nil {
+		return
+	}
+
+	if err = enc.String(m.Reason); err != nil {
+		return
+	}
+
+	var timestamp int64
+	if !m.Timestamp.IsZero() {
+		timestamp = m.Timestamp.UnixNano()
+	}
+
+	// Write timestamp as unix nanoseconds
+	if err = enc.Int64(timestamp); err != nil {
+		return
+	}
+
+	return enc.Uint64(m.Position)
+}
+
+// UnmarshalEnkodo is a enkodo decoding helper func
+func (m *Metadata) UnmarshalEnkodo(dec *enkodo.Decoder) (err error) {
+	var version uint8
+	switch version, err = dec.Uint8(); {
+	case err == io.EOF:
+		// Action was written without metadata
+		return nil
+	case err != nil:
+		return
+	case version == 0:
+		return fmt.Errorf("invalid metadata version, <%d> is not supported", version)
+	}
+
+	// Newer versions only append fields, so the fields of the current version can always be read
+	if m.ActorID, err = dec.String(); err != nil {
+		return
+	}
+
+	if m.RequestID, err = dec.String(); err != nil {
+		return
+	}
+
+	if m.Reason, err = dec.String(); err != nil {
+		return
+	}
+
+	var timestamp int64
+	if timestamp, err = dec.Int64(); err != nil {
+		return
+	}
+
+	if timestamp != 0 {
+		m.Timestamp = time.Unix(0, timestamp)
+	}
+
+	if version < 2 {
+		// Position was added within version 2
+		return
+	}
+
+	m.Position, err = dec.Uint64()
+	return
+}
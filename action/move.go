@@ -0,0 +1,39 @@
+package action
+
+import (
+	"bytes"
+
+	"github.com/mojura/enkodo"
+)
+
+// Move represents the move of an entry to a new entry ID
+type Move struct {
+	// EntryID is the new entry ID of the entry
+	EntryID string
+	// Value of the moved entry, empty when the tombstone of a deleted entry was moved
+	Value []byte
+}
+
+// MarshalEnkodo is a enkodo encoding helper func
+func (m *Move) MarshalEnkodo(enc *enkodo.Encoder) (err error) {
+	if err = enc.String(m.EntryID); err != nil {
+		return
+	}
+
+	return enc.Bytes(m.Value)
+}
+
+// UnmarshalEnkodo is a enkodo decoding helper func
+func (m *Move) UnmarshalEnkodo(dec *enkodo.Decoder) (err error) {
+	if m.EntryID, err = dec.String(); err != nil {
+		return
+	}
+
+	return dec.Bytes(&m.Value)
+}
+
+// Move will decode the move of a move action
+func (a *Action) Move() (m Move, err error) {
+	err = enkodo.NewReader(bytes.NewReader(a.Value)).Decode(&m)
+	return
+}
@@ -11,6 +11,14 @@ const (
 	TypeDelete
 	// TypeComment represents a comment block
 	TypeComment
+	// TypePurge represents a purge block, which permanently removes a soft-deleted entry
+	TypePurge
+	// TypeSetLookup represents a set lookup block
+	TypeSetLookup
+	// TypeRemoveLookup represents a remove lookup block
+	TypeRemoveLookup
+	// TypeMove represents a move block, which moves an entry to a new entry ID
+	TypeMove
 )
 
 const invalidactiontypeLayout = "invalid type, <%d> is not supported"
@@ -24,6 +32,10 @@ func (t Type) Validate() (err error) {
 	case TypeWrite:
 	case TypeDelete:
 	case TypeComment:
+	case TypePurge:
+	case TypeSetLookup:
+	case TypeRemoveLookup:
+	case TypeMove:
 
 	default:
 		// Currently set as an unsupported type, return error
@@ -44,6 +56,14 @@ func (t Type) String() string {
 		return "delete"
 	case TypeComment:
 		return "comment"
+	case TypePurge:
+		return "purge"
+	case TypeSetLookup:
+		return "setLookup"
+	case TypeRemoveLookup:
+		return "removeLookup"
+	case TypeMove:
+		return "move"
 
 	default:
 		// Current type is not supported, return invalid
@@ -15,6 +15,13 @@ func MakeWriter(w BlockWriter) (a Writer) {
 type Writer struct {
 	buf *bytes.Buffer
 	w   BlockWriter
+
+	md Metadata
+}
+
+// SetMetadata will set the metadata included with each block written by the writer
+func (w *Writer) SetMetadata(md Metadata) {
+	w.md = md
 }
 
 func (w *Writer) Write(entryID, value []byte) (err error) {
@@ -25,11 +32,53 @@ func (w *Writer) Delete(entryID []byte) (err error) {
 	return w.addBlock(TypeDelete, entryID, nil)
 }
 
+// DeleteValue will write a delete which includes the deleted value
+func (w *Writer) DeleteValue(entryID, value []byte) (err error) {
+	return w.addBlock(TypeDelete, entryID, value)
+}
+
+// Comment will write a comment block, comments are not applied to entries
+func (w *Writer) Comment(value []byte) (err error) {
+	return w.addBlock(TypeComment, nil, value)
+}
+
+func (w *Writer) Purge(entryID []byte) (err error) {
+	return w.addBlock(TypePurge, entryID, nil)
+}
+
+// Move will write a move of an entry to a new entry ID
+func (w *Writer) Move(entryID []byte, m Move) (err error) {
+	var buf bytes.Buffer
+	if err = enkodo.NewWriter(&buf).Encode(&m); err != nil {
+		return
+	}
+
+	return w.addBlock(TypeMove, entryID, buf.Bytes())
+}
+
+func (w *Writer) SetLookup(entryID []byte, l Lookup) (err error) {
+	return w.addLookupBlock(TypeSetLookup, entryID, l)
+}
+
+func (w *Writer) RemoveLookup(entryID []byte, l Lookup) (err error) {
+	return w.addLookupBlock(TypeRemoveLookup, entryID, l)
+}
+
+func (w *Writer) addLookupBlock(t Type, entryID []byte, l Lookup) (err error) {
+	var buf bytes.Buffer
+	if err = enkodo.NewWriter(&buf).Encode(&l); err != nil {
+		return
+	}
+
+	return w.addBlock(t, entryID, buf.Bytes())
+}
+
 func (w *Writer) addBlock(t Type, entryID, value []byte) (err error) {
 	var a Action
 	a.Key = entryID
 	a.Value = value
 	a.Type = t
+	a.Metadata = w.md
 
 	w.buf.Reset()
 	if err = enkodo.NewWriter(w.buf).Encode(&a); err != nil {
@@ -0,0 +1,207 @@
+package mojura
+
+var _ filterCursor = &andCursor[*Entry]{}
+
+func newAndCursor[T Value](txn *Transaction[T], fs []Filter) (cur *andCursor[T], err error) {
+	var c andCursor[T]
+	if len(fs) == 0 {
+		err = ErrEmptyFilters
+		return
+	}
+
+	var p Plan
+	// Plan the filters so the most selective filter will drive iteration
+	if p, err = txn.plan(fs); err != nil {
+		return
+	}
+
+	fcs := make([]filterCursor, 0, len(fs))
+	for _, f := range p.getFilters() {
+		var fc filterCursor
+		if fc, err = newFilterCursor(txn, f); err != nil {
+			return
+		}
+
+		fcs = append(fcs, fc)
+	}
+
+	c.txn = txn
+	c.primary = fcs[0]
+	if len(fs) > 1 {
+		c.secondary = fcs[1:]
+	}
+
+	cur = &c
+	return
+}
+
+// andCursor will iterate through the primary cursor and only return the
+// entries which exist within all of the secondary cursors
+type andCursor[T Value] struct {
+	txn *Transaction[T]
+
+	primary   filterCursor
+	secondary []filterCursor
+}
+
+func (c *andCursor[T]) getCurrentRelationshipID() (relationshipID string) {
+	return c.primary.getCurrentRelationshipID()
+}
+
+func (c *andCursor[T]) isForwardMatch(entryID []byte) (isMatch bool, err error) {
+	for _, secondary := range c.secondary {
+		if isMatch, err = secondary.HasForward(entryID); err != nil {
+			isMatch = false
+			return
+		}
+
+		if !isMatch {
+			return
+		}
+	}
+
+	return true, nil
+}
+
+func (c *andCursor[T]) isReverseMatch(entryID []byte) (isMatch bool, err error) {
+	for _, secondary := range c.secondary {
+		if isMatch, err = secondary.HasReverse(entryID); err != nil {
+			isMatch = false
+			return
+		}
+
+		if !isMatch {
+			return
+		}
+	}
+
+	return true, nil
+}
+
+func (c *andCursor[T]) nextUntilMatch(entryID []byte) (matchEntryID []byte, err error) {
+	var isMatch bool
+	for err == nil {
+		isMatch, err = c.isForwardMatch(entryID)
+		switch {
+		case err != nil:
+			return
+		case isMatch:
+			matchEntryID = entryID
+			return
+
+		default:
+			entryID, err = c.primary.Next()
+		}
+	}
+
+	return
+}
+
+func (c *andCursor[T]) prevUntilMatch(entryID []byte) (matchEntryID []byte, err error) {
+	var isMatch bool
+	for err == nil {
+		isMatch, err = c.isReverseMatch(entryID)
+		switch {
+		case err != nil:
+			return
+		case isMatch:
+			matchEntryID = entryID
+			return
+
+		default:
+			entryID, err = c.primary.Prev()
+		}
+	}
+
+	return
+}
+
+// SeekForward will seek the provided ID and move forward until match
+func (c *andCursor[T]) SeekForward(relationshipID, seekID []byte) (entryID []byte, err error) {
+	if entryID, err = c.primary.SeekForward(relationshipID, seekID); err != nil {
+		return
+	}
+
+	return c.nextUntilMatch(entryID)
+}
+
+// SeekReverse will seek the provided ID and move reverse until match
+func (c *andCursor[T]) SeekReverse(relationshipID, seekID []byte) (entryID []byte, err error) {
+	if entryID, err = c.primary.SeekReverse(relationshipID, seekID); err != nil {
+		return
+	}
+
+	return c.prevUntilMatch(entryID)
+}
+
+// First will return the first entry
+func (c *andCursor[T]) First() (entryID []byte, err error) {
+	if entryID, err = c.primary.First(); err != nil {
+		return
+	}
+
+	return c.nextUntilMatch(entryID)
+}
+
+// Last will return the last entry
+func (c *andCursor[T]) Last() (entryID []byte, err error) {
+	if entryID, err = c.primary.Last(); err != nil {
+		return
+	}
+
+	return c.prevUntilMatch(entryID)
+}
+
+// Next will return the next entry
+func (c *andCursor[T]) Next() (entryID []byte, err error) {
+	if entryID, err = c.primary.Next(); err != nil {
+		return
+	}
+
+	return c.nextUntilMatch(entryID)
+}
+
+// Prev will return the previous entry
+func (c *andCursor[T]) Prev() (entryID []byte, err error) {
+	if entryID, err = c.primary.Prev(); err != nil {
+		return
+	}
+
+	return c.prevUntilMatch(entryID)
+}
+
+// HasForward will determine if an entry exists in a forward direction
+func (c *andCursor[T]) HasForward(entryID []byte) (ok bool, err error) {
+	if ok, err = c.primary.HasForward(entryID); !ok || err != nil {
+		return
+	}
+
+	for _, secondary := range c.secondary {
+		if ok, err = secondary.HasForward(entryID); !ok || err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// HasReverse will determine if an entry exists in a reverse direction
+func (c *andCursor[T]) HasReverse(entryID []byte) (ok bool, err error) {
+	if ok, err = c.primary.HasReverse(entryID); !ok || err != nil {
+		return
+	}
+
+	for _, secondary := range c.secondary {
+		if ok, err = secondary.HasReverse(entryID); !ok || err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (c *andCursor[T]) teardown() {
+	c.txn = nil
+	c.primary = nil
+	c.secondary = nil
+}
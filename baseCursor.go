@@ -107,8 +107,8 @@ func (c *baseCursor[T]) Prev() (val T, err error) {
 	return c.get(k, v)
 }
 
-func (c *baseCursor[T]) get(_, bs []byte) (val T, err error) {
-	return c.txn.m.newValueFromBytes(bs)
+func (c *baseCursor[T]) get(entryID, bs []byte) (val T, err error) {
+	return c.txn.newValueFromBytes(entryID, bs)
 }
 
 func (c *baseCursor[T]) teardown() {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/hatchify/errors"
+	"github.com/mojura/mojura/action"
 )
 
 func newBatcher[T Value](m *Mojura[T]) *batcher[T] {
@@ -29,6 +30,8 @@ func (b *batcher[T]) performCalls(txn *Transaction[T], cs calls[T]) (failIndex i
 	for i, c := range cs {
 		// Update transaction context
 		txn.cc.update(c.ctx)
+		// Metadata set by a previous call must not be attributed to this call
+		txn.actionMetadata = action.Metadata{}
 
 		// Pass call func to recoverCall
 		if err = recoverCall(txn, c.fn); err != nil {
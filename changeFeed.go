@@ -0,0 +1,591 @@
+package mojura
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hatchify/errors"
+	"github.com/mojura/enkodo"
+	"github.com/mojura/kiroku"
+	"github.com/mojura/mojura/action"
+	"github.com/mojura/mojura/filters"
+)
+
+// historyListSize is the number of history filenames listed at a time
+const historyListSize = 64
+
+// Change represents a committed write or delete of an entry
+type Change[T Value] struct {
+	// Position of the change, positions are sequential for each committed change
+	Position uint64
+	// Type of change, will be action.TypeWrite or action.TypeDelete
+	Type action.Type
+	// EntryID of the changed entry
+	EntryID string
+	// Value of the entry, deletes will contain the deleted value. The value is decoded once
+	// for each change and shared between subscriptions, it must not be modified
+	Value T
+	// Previous value of the entry, set for writes which replaced an existing entry
+	// Note: Changes resumed from the history do not contain the previous value
+	Previous T
+
+	// value and previous are the encoded values, decoded once the change has been committed
+	value    []byte
+	previous []byte
+	// relationships of the value and the previous value, used to match filters
+	relationships         Relationships
+	previousRelationships Relationships
+}
+
+func newChangeFeed[T Value](backlogSize, bufferSize int, readHistory readHistoryFn[T]) *changeFeed[T] {
+	var f changeFeed[T]
+	f.subscriptions = map[*subscription[T]]struct{}{}
+	f.backlogSize = backlogSize
+	f.bufferSize = bufferSize
+	f.readHistory = readHistory
+	return &f
+}
+
+// changeFeed publishes committed changes to subscriptions
+type changeFeed[T Value] struct {
+	// commitMux is held for the duration of a write transaction
+	commitMux sync.Mutex
+	// publishMux is acquired before the commitMux is released and held until the changes of
+	// the transaction have been published. This ensures changes are published in commit order,
+	// while changes are decoded without holding up the next write transaction
+	publishMux sync.Mutex
+
+	mux           sync.Mutex
+	subscriptions map[*subscription[T]]struct{}
+	// backlog contains the most recent changes, used to resume subscriptions
+	backlog     []Change[T]
+	backlogSize int
+	bufferSize  int
+	// position is the position of the last published change
+	position uint64
+
+	// readHistory is used to resume subscriptions from positions which have left the backlog,
+	// nil when the history is not available
+	readHistory readHistoryFn[T]
+
+	closed bool
+}
+
+type readHistoryFn[T Value] func(ctx context.Context, position uint64) ([]Change[T], error)
+
+// initPosition will set the change feed and replication positions to the position of the last
+// committed change
+func (m *Mojura[T]) initPosition() (err error) {
+	return m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+		if err = txn.ensureMeta(); err != nil {
+			return
+		}
+
+		m.feed.setPosition(txn.meta.Position)
+		m.rs.setPosition(txn.meta.Position)
+		return
+	})
+}
+
+// getHistoryChanges will read the changes following the position from the chunks exported to
+// the Source. Snapshots are skipped, as they contain the state of the entries rather than changes
+func (m *Mojura[T]) getHistoryChanges(ctx context.Context, position uint64) (changes []Change[T], err error) {
+	src := m.opts.Source
+	name := m.opts.FullName()
+	last := position
+	var lastFilename string
+	for {
+		var filenames []string
+		switch filenames, err = src.GetNextList(ctx, name, lastFilename, historyListSize); err {
+		case nil:
+		case io.EOF:
+			err = nil
+		default:
+			return
+		}
+
+		if len(filenames) == 0 {
+			break
+		}
+
+		for _, filename := range filenames {
+			lastFilename = filename
+			parsed, perr := kiroku.ParseFilename(filename)
+			if perr != nil || parsed.Name != name || parsed.Filetype != kiroku.TypeChunk {
+				continue
+			}
+
+			if changes, last, err = m.appendChunkChanges(ctx, changes, filename, last); err != nil {
+				err = fmt.Errorf("error reading changes from <%s>: %v", filename, err)
+				return
+			}
+		}
+	}
+
+	if len(changes) == 0 || changes[0].Position != position+1 {
+		// The history does not contain the changes following the position
+		err = ErrPositionUnavailable
+		return
+	}
+
+	return
+}
+
+// appendChunkChanges will append the changes of a chunk which follow the last position
+func (m *Mojura[T]) appendChunkChanges(ctx context.Context, changes []Change[T], filename string, last uint64) (out []Change[T], newLast uint64, err error) {
+	var buf bytes.Buffer
+	if err = m.opts.Source.Import(ctx, m.opts.FullName(), filename, &buf); err != nil {
+		return
+	}
+
+	r := kiroku.NewReader(bytes.NewReader(buf.Bytes()))
+	err = r.ForEach(0, func(b kiroku.Block) (err error) {
+		var a action.Action
+		if err = enkodo.NewReader(bytes.NewReader(b)).Decode(&a); err != nil {
+			return
+		}
+
+		var cs []Change[T]
+		if cs, err = newActionChanges[T](&a); err != nil {
+			return
+		}
+
+		for _, c := range cs {
+			// Positions are only appended once, in case blocks were written more than once
+			if c.Position <= last {
+				continue
+			}
+
+			if err = m.decodeChange(&c); err != nil {
+				return
+			}
+
+			changes = append(changes, c)
+			last = c.Position
+		}
+
+		return
+	})
+
+	return changes, last, err
+}
+
+// newActionChanges will return the changes represented by an action
+func newActionChanges[T Value](a *action.Action) (changes []Change[T], err error) {
+	position := a.Metadata.Position
+	if position == 0 {
+		// Action is not a change, or was written before positions were recorded
+		return
+	}
+
+	switch a.Type {
+	case action.TypeWrite, action.TypeDelete:
+		changes = append(changes, Change[T]{Position: position, Type: a.Type, EntryID: string(a.Key), value: a.Value})
+	case action.TypeMove:
+		var mv action.Move
+		if mv, err = a.Move(); err != nil {
+			return
+		}
+
+		changes = append(changes,
+			Change[T]{Position: position, Type: action.TypeDelete, EntryID: string(a.Key), value: mv.Value},
+			Change[T]{Position: position + 1, Type: action.TypeWrite, EntryID: mv.EntryID, value: mv.Value},
+		)
+	}
+
+	return
+}
+
+// decodeChange will decode the values of a change and the relationships used to match filters
+func (m *Mojura[T]) decodeChange(c *Change[T]) (err error) {
+	if c.Value, err = m.newValueFromBytes(c.value); err != nil {
+		return fmt.Errorf("error decoding value of <%s>: %v", c.EntryID, err)
+	}
+
+	c.relationships = m.getRelationships(c.Value)
+	if len(c.previous) == 0 {
+		return
+	}
+
+	if c.Previous, err = m.newValueFromBytes(c.previous); err != nil {
+		return fmt.Errorf("error decoding previous value of <%s>: %v", c.EntryID, err)
+	}
+
+	c.previousRelationships = m.getRelationships(c.Previous)
+	return
+}
+
+// decodeChanges will decode the changes of a committed transaction. Changes which cannot be
+// decoded are published without their values, so the positions remain sequential
+func (m *Mojura[T]) decodeChanges(changes []Change[T]) {
+	for i := range changes {
+		if err := m.decodeChange(&changes[i]); err != nil {
+			m.out.Error(err.Error())
+		}
+	}
+}
+
+func (f *changeFeed[T]) setPosition(position uint64) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.position = position
+}
+
+func (f *changeFeed[T]) getPosition() (position uint64) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.position
+}
+
+func (f *changeFeed[T]) subscribe(ctx context.Context, match changeMatchFn[T], position uint64, resume bool) (ch <-chan Change[T], err error) {
+	var replay []Change[T]
+	if resume {
+		if replay, err = f.getReplay(ctx, position); err != nil {
+			return
+		}
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.closed {
+		err = errors.ErrIsClosed
+		return
+	}
+
+	if resume {
+		last := position
+		if len(replay) > 0 {
+			last = replay[len(replay)-1].Position
+		}
+
+		// Changes may have been committed since the replay was gathered
+		var changes []Change[T]
+		if changes, err = f.getChangesAfter(last); err != nil {
+			return
+		}
+
+		replay = append(replay, changes...)
+	}
+
+	var s subscription[T]
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.ch = make(chan Change[T], f.bufferSize)
+	s.match = match
+	// Subscriptions without changes to replay receive published changes right away
+	s.ready = len(replay) == 0
+	f.subscriptions[&s] = struct{}{}
+
+	go s.run(f, replay)
+	ch = s.ch
+	return
+}
+
+// getReplay will return the changes following the position, from the backlog when available
+// and otherwise from the history
+func (f *changeFeed[T]) getReplay(ctx context.Context, position uint64) (changes []Change[T], err error) {
+	f.mux.Lock()
+	changes, err = f.getChangesAfter(position)
+	current := f.position
+	f.mux.Unlock()
+
+	if err != ErrPositionUnavailable || position > current || f.readHistory == nil {
+		return
+	}
+
+	// The history is read without holding the lock, as it may take a while
+	return f.readHistory(ctx, position)
+}
+
+func (f *changeFeed[T]) getChangesAfter(position uint64) (changes []Change[T], err error) {
+	switch {
+	case position > f.position:
+		err = ErrPositionUnavailable
+		return
+	case position == f.position:
+		return
+	case len(f.backlog) == 0 || f.backlog[0].Position > position+1:
+		// Changes following the position have already left the backlog
+		err = ErrPositionUnavailable
+		return
+	}
+
+	start := int(position + 1 - f.backlog[0].Position)
+	changes = append(changes, f.backlog[start:]...)
+	return
+}
+
+// catchUp will return the changes published since the last replayed change. Once there are
+// no changes left to replay, the subscription is marked as ready to receive published changes
+func (f *changeFeed[T]) catchUp(s *subscription[T]) (changes []Change[T], err error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if changes, err = f.getChangesAfter(s.position); err != nil || len(changes) > 0 {
+		return
+	}
+
+	s.ready = true
+	return
+}
+
+func (f *changeFeed[T]) unsubscribe(s *subscription[T]) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	delete(f.subscriptions, s)
+	close(s.ch)
+}
+
+func (f *changeFeed[T]) publish(changes []Change[T]) {
+	if len(changes) == 0 {
+		return
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for _, c := range changes {
+		f.position = c.Position
+		f.appendBacklog(c)
+		for s := range f.subscriptions {
+			if s.ready {
+				s.send(&c)
+			}
+		}
+	}
+}
+
+func (f *changeFeed[T]) appendBacklog(c Change[T]) {
+	if f.backlogSize <= 0 {
+		return
+	}
+
+	if f.backlog = append(f.backlog, c); len(f.backlog) > f.backlogSize {
+		f.backlog = f.backlog[len(f.backlog)-f.backlogSize:]
+	}
+}
+
+func (f *changeFeed[T]) close() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.closed = true
+	for s := range f.subscriptions {
+		s.cancel()
+	}
+}
+
+type subscription[T Value] struct {
+	ctx    context.Context
+	cancel func()
+
+	ch    chan Change[T]
+	match changeMatchFn[T]
+
+	// position is the position of the last replayed change
+	position uint64
+	// ready is set once the replayed changes have been sent, protected by the feed mutex
+	ready bool
+}
+
+func (s *subscription[T]) run(f *changeFeed[T], replay []Change[T]) {
+	defer s.cancel()
+	defer f.unsubscribe(s)
+	for len(replay) > 0 {
+		for i := range replay {
+			if !s.replay(&replay[i]) {
+				return
+			}
+		}
+
+		var err error
+		if replay, err = f.catchUp(s); err != nil {
+			// The subscription fell behind the backlog while replaying
+			return
+		}
+	}
+
+	<-s.ctx.Done()
+}
+
+// replay will send a replayed change, waiting for the subscriber to receive it
+func (s *subscription[T]) replay(c *Change[T]) (ok bool) {
+	s.position = c.Position
+	if !s.match(c) {
+		return true
+	}
+
+	select {
+	case s.ch <- *c:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// send will send a published change without blocking. A subscription with a full buffer is
+// ended rather than holding up the writes, the subscriber is able to resume from the
+// position of the last received change
+func (s *subscription[T]) send(c *Change[T]) {
+	if s.ctx.Err() != nil || !s.match(c) {
+		return
+	}
+
+	select {
+	case s.ch <- *c:
+	default:
+		s.cancel()
+	}
+}
+
+type changeMatchFn[T Value] func(*Change[T]) bool
+
+func (m *Mojura[T]) newChangeMatchFn(o *FilteringOpts) (fn changeMatchFn[T], err error) {
+	if o == nil || len(o.Filters) == 0 {
+		fn = func(*Change[T]) bool { return true }
+		return
+	}
+
+	for _, f := range o.Filters {
+		if err = m.validateChangeFilter(f); err != nil {
+			return
+		}
+	}
+
+	fn = func(c *Change[T]) bool {
+		if m.isChangeMatchAll(c.EntryID, c.relationships, o.Filters) {
+			return true
+		}
+
+		// Writes which move an entry out of the filters are matched by the previous value
+		return len(c.previous) > 0 && m.isChangeMatchAll(c.EntryID, c.previousRelationships, o.Filters)
+	}
+
+	return
+}
+
+func (m *Mojura[T]) isChangeMatchAll(entryID string, rs Relationships, fs []Filter) (ok bool) {
+	for _, f := range fs {
+		if !m.isChangeMatch(entryID, rs, f) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *Mojura[T]) validateChangeFilter(f Filter) (err error) {
+	switch filter := f.(type) {
+	case *filters.MatchFilter:
+		return m.validateRelationshipKey(filter.RelationshipKey)
+	case *filters.InverseMatchFilter:
+		return m.validateRelationshipKey(filter.RelationshipKey)
+	case *filters.ComparisonFilter:
+		if len(filter.RelationshipKey) == 0 {
+			return
+		}
+
+		return m.validateRelationshipKey(filter.RelationshipKey)
+	case *filters.OrFilter:
+		for _, child := range filter.Filters {
+			if err = m.validateChangeFilter(child); err != nil {
+				return
+			}
+		}
+
+		return
+	case *filters.AndFilter:
+		for _, child := range filter.Filters {
+			if err = m.validateChangeFilter(child); err != nil {
+				return
+			}
+		}
+
+		return
+
+	default:
+		return fmt.Errorf("filter of %T is not supported", filter)
+	}
+}
+
+func (m *Mojura[T]) validateRelationshipKey(relationshipKey string) (err error) {
+	if _, ok := m.getRelationshipIndex(relationshipKey); !ok {
+		return ErrRelationshipNotFound
+	}
+
+	return
+}
+
+func (m *Mojura[T]) getRelationshipIndex(relationshipKey string) (index int, ok bool) {
+	for i, relationship := range m.relationships {
+		if string(relationship) == relationshipKey {
+			return i, true
+		}
+	}
+
+	return
+}
+
+// isChangeMatch will determine if a changed entry matches a filter, using the same
+// semantics as the filter cursors
+func (m *Mojura[T]) isChangeMatch(entryID string, rs Relationships, f Filter) (ok bool) {
+	switch filter := f.(type) {
+	case *filters.MatchFilter:
+		return m.getRelationship(rs, filter.RelationshipKey).Has(filter.RelationshipID)
+	case *filters.InverseMatchFilter:
+		return !m.getRelationship(rs, filter.RelationshipKey).Has(filter.RelationshipID)
+	case *filters.ComparisonFilter:
+		if len(filter.RelationshipKey) == 0 {
+			return isComparisonMatch(filter, entryID)
+		}
+
+		for _, relationshipID := range m.getRelationship(rs, filter.RelationshipKey) {
+			if isComparisonMatch(filter, relationshipID) {
+				return true
+			}
+		}
+
+		return false
+	case *filters.OrFilter:
+		for _, child := range filter.Filters {
+			if m.isChangeMatch(entryID, rs, child) {
+				return true
+			}
+		}
+
+		return false
+	case *filters.AndFilter:
+		for _, child := range filter.Filters {
+			if !m.isChangeMatch(entryID, rs, child) {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		return false
+	}
+}
+
+func (m *Mojura[T]) getRelationship(rs Relationships, relationshipKey string) (r Relationship) {
+	index, ok := m.getRelationshipIndex(relationshipKey)
+	if !ok || index >= len(rs) {
+		return
+	}
+
+	return rs[index]
+}
+
+func isComparisonMatch(f *filters.ComparisonFilter, id string) (ok bool) {
+	switch {
+	case len(f.RangeStart) > 0 && id < f.RangeStart:
+		return false
+	case len(f.RangeEnd) > 0 && id > f.RangeEnd:
+		return false
+	case f.Comparison == nil:
+		return true
+	}
+
+	ok, err := f.Comparison(id)
+	return ok && err == nil
+}
@@ -10,6 +10,8 @@ type Entry struct {
 	CreatedAt int64 `json:"createdAt"`
 	// Unix timestamp of last Entry update
 	UpdatedAt int64 `json:"updatedAt"`
+	// Version of the Entry, incremented on each write
+	Version int64 `json:"version"`
 }
 
 // GetID will get the message ID
@@ -27,6 +29,11 @@ func (e *Entry) GetUpdatedAt() (updatedAt int64) {
 	return e.UpdatedAt
 }
 
+// GetVersion will get the version
+func (e *Entry) GetVersion() (version int64) {
+	return e.Version
+}
+
 // GetRelationshipIDs will get the associated relationship IDs
 // Deprecated: This method is now deprecated. The method has been kept and the signature
 // has been changed to ensure previous use of this method would be easily caught by
@@ -37,7 +44,8 @@ func (e *Entry) GetRelationshipIDs() (deprecated string) {
 }
 
 // GetRelationships will get the associated relationships
-// Note: This will have to be replaced by the including entry if relationships are needed
+// Note: This will have to be replaced by the including entry if relationships are needed,
+// unless the relationship fields are tagged (e.g. `mojura:"rel=users"`)
 func (e *Entry) GetRelationships() (r Relationships) {
 	return
 }
@@ -56,3 +64,8 @@ func (e *Entry) SetCreatedAt(createdAt int64) {
 func (e *Entry) SetUpdatedAt(updatedAt int64) {
 	e.UpdatedAt = updatedAt
 }
+
+// SetVersion will set the version
+func (e *Entry) SetVersion(version int64) {
+	e.Version = version
+}
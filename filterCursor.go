@@ -14,6 +14,10 @@ func newFilterCursor[T Value](txn *Transaction[T], f Filter) (fc filterCursor, e
 		return newInverseMatchCursor(txn, n)
 	case *filters.ComparisonFilter:
 		return newComparisonCursor(txn, n)
+	case *filters.OrFilter:
+		return newOrCursor(txn, n)
+	case *filters.AndFilter:
+		return newAndCursor(txn, toFilters(n.Filters))
 	default:
 		err = fmt.Errorf("filter of %T is not supported", n)
 		return
@@ -0,0 +1,27 @@
+package filters
+
+// Or creates a new union filter
+func Or(fs ...interface{}) *OrFilter {
+	var o OrFilter
+	o.Filters = fs
+	return &o
+}
+
+// OrFilter will match entries which match at least one of the provided filters
+// Note: Matching entries are returned in entry ID order
+type OrFilter struct {
+	Filters []interface{} `json:"filters"`
+}
+
+// And creates a new intersection filter
+func And(fs ...interface{}) *AndFilter {
+	var a AndFilter
+	a.Filters = fs
+	return &a
+}
+
+// AndFilter will match entries which match all of the provided filters
+// Note: This is useful for nesting intersections within an OrFilter
+type AndFilter struct {
+	Filters []interface{} `json:"filters"`
+}
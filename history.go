@@ -0,0 +1,389 @@
+package mojura
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/mojura/backend"
+	"github.com/mojura/enkodo"
+	"github.com/mojura/kiroku"
+	"github.com/mojura/mojura/action"
+)
+
+// revisionHeaderSize is the size of a revision record header: type (1), schema version (8), chunk lower bound (8)
+const revisionHeaderSize = 17
+
+// Revision represents a past version of an entry
+type Revision[T Value] struct {
+	// Position of the revision within the change feed
+	Position uint64 `json:"position"`
+	// Type of action, will be action.TypeWrite or action.TypeDelete
+	Type action.Type `json:"type"`
+	// Timestamp is when the revision was written by the primary
+	Timestamp time.Time `json:"timestamp"`
+	// ActorID is the ID of the actor who wrote the revision
+	ActorID string `json:"actorID,omitempty"`
+	// RequestID is the ID of the request which wrote the revision
+	RequestID string `json:"requestID,omitempty"`
+	// Reason is a description of why the revision was written
+	Reason string `json:"reason,omitempty"`
+	// Value of the entry, deletes will contain the deleted value
+	Value T `json:"value"`
+}
+
+// revisionRecord is the history index record of a revision. The value of the revision is not
+// retained, it's read from the block at the position of the revision within the history
+type revisionRecord struct {
+	actionType action.Type
+	// schemaVersion is the schema version the revision was written with
+	schemaVersion int64
+	// chunkLowerBound is at or before the creation time of the chunk containing the revision
+	chunkLowerBound int64
+	// metadata is the metadata of the block, which includes the position of the revision
+	metadata action.Metadata
+}
+
+func (r *revisionRecord) marshal() (record []byte, err error) {
+	buf := bytes.NewBuffer(make([]byte, revisionHeaderSize))
+	if err = enkodo.NewWriter(buf).Encode(&r.metadata); err != nil {
+		return
+	}
+
+	record = buf.Bytes()
+	record[0] = byte(r.actionType)
+	binary.BigEndian.PutUint64(record[1:9], uint64(r.schemaVersion))
+	binary.BigEndian.PutUint64(record[9:17], uint64(r.chunkLowerBound))
+	return
+}
+
+func (r *revisionRecord) unmarshal(record []byte) (err error) {
+	if len(record) < revisionHeaderSize {
+		return ErrInvalidRevision
+	}
+
+	r.actionType = action.Type(record[0])
+	r.schemaVersion = int64(binary.BigEndian.Uint64(record[1:9]))
+	r.chunkLowerBound = int64(binary.BigEndian.Uint64(record[9:17]))
+	return enkodo.NewReader(bytes.NewReader(record[revisionHeaderSize:])).Decode(&r.metadata)
+}
+
+func (t *Transaction[T]) getHistoryBucket(entryID []byte, create bool) (bkt backend.Bucket, err error) {
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	var historyBkt backend.Bucket
+	if historyBkt = t.txn.GetBucket(historyBktKey); historyBkt == nil {
+		err = ErrNotInitialized
+		return
+	}
+
+	if !create {
+		bkt = historyBkt.GetBucket(entryID)
+		return
+	}
+
+	return historyBkt.GetOrCreateBucket(entryID)
+}
+
+// indexRevision will add the position of a revision to the history index of an entry
+func (t *Transaction[T]) indexRevision(actionType action.Type, entryID []byte, md action.Metadata) (err error) {
+	if !t.m.opts.IndexHistory {
+		return
+	}
+
+	if md.Position == 0 {
+		// Blocks without a position cannot be located within the history. This includes the
+		// blocks of snapshots, which contain the state of the entries rather than revisions
+		return
+	}
+
+	var bkt backend.Bucket
+	if bkt, err = t.getHistoryBucket(entryID, true); err != nil {
+		return
+	}
+
+	r := revisionRecord{
+		actionType:      actionType,
+		schemaVersion:   t.getCurrentSchemaVersion(),
+		chunkLowerBound: t.chunkCreatedAt,
+		metadata:        md,
+	}
+
+	var record []byte
+	if record, err = r.marshal(); err != nil {
+		return
+	}
+
+	// Revisions are keyed by position, so importing a block more than once is idempotent
+	return bkt.Put(encodeCount(int64(md.Position)), record)
+}
+
+// getRevisionRecords will return the history index records of an entry, oldest first
+func (t *Transaction[T]) getRevisionRecords(entryID []byte) (records []revisionRecord, err error) {
+	if !t.m.opts.IndexHistory {
+		err = ErrHistoryNotIndexed
+		return
+	}
+
+	if t.m.opts.Source == nil {
+		err = ErrSourceNotSet
+		return
+	}
+
+	var bkt backend.Bucket
+	if bkt, err = t.getHistoryBucket(entryID, false); err != nil {
+		return
+	}
+
+	if bkt == nil {
+		err = ErrEntryNotFound
+		return
+	}
+
+	err = bkt.ForEach(func(key, record []byte) (err error) {
+		var r revisionRecord
+		if err = r.unmarshal(record); err != nil {
+			err = fmt.Errorf("error reading revision %d: %v", decodeCount(key), err)
+			return
+		}
+
+		records = append(records, r)
+		return
+	})
+
+	return
+}
+
+func (m *Mojura[T]) history(ctx context.Context, entryID string) (revisions []Revision[T], err error) {
+	var records []revisionRecord
+	if err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
+		records, err = txn.getRevisionRecords([]byte(entryID))
+		return
+	}); err != nil {
+		return
+	}
+
+	// The blocks of the revisions are read once the transaction has ended, so reading the
+	// history does not hold the transaction open
+	hr := newHistoryReader(ctx, m.opts)
+	for i := range records {
+		var r Revision[T]
+		if r, err = m.newRevision(hr, []byte(entryID), &records[i]); err != nil {
+			err = fmt.Errorf("error reading revision %d: %v", records[i].metadata.Position, err)
+			return
+		}
+
+		revisions = append(revisions, r)
+	}
+
+	return
+}
+
+func (m *Mojura[T]) newRevision(hr *historyReader, entryID []byte, rr *revisionRecord) (r Revision[T], err error) {
+	var a *action.Action
+	if a, err = hr.get(rr); err != nil {
+		return
+	}
+
+	var bs []byte
+	// Revisions are upgraded from the schema version they were written with
+	if bs, err = m.upgradeFrom(entryID, a.Value, rr.schemaVersion); err != nil {
+		return
+	}
+
+	if r.Value, err = m.newValueFromBytes(bs); err != nil {
+		return
+	}
+
+	r.Position = rr.metadata.Position
+	r.Type = rr.actionType
+	r.Timestamp = rr.metadata.Timestamp
+	r.ActorID = rr.metadata.ActorID
+	r.RequestID = rr.metadata.RequestID
+	r.Reason = rr.metadata.Reason
+	return
+}
+
+// moveHistory will move the revisions of an entry to a new entry ID
+func (t *Transaction[T]) moveHistory(entryID, newEntryID []byte) (err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getHistoryBucket(entryID, false); err != nil || bkt == nil {
+		return
+	}
+
+	var newBkt backend.Bucket
+	if newBkt, err = t.getHistoryBucket(newEntryID, true); err != nil {
+		return
+	}
+
+	if err = bkt.ForEach(func(key, record []byte) (err error) {
+		return newBkt.Put(key, record)
+	}); err != nil {
+		return
+	}
+
+	return t.txn.GetBucket(historyBktKey).DeleteBucket(entryID)
+}
+
+func newHistoryReader(ctx context.Context, opts *Opts) *historyReader {
+	var h historyReader
+	h.ctx = ctx
+	h.opts = opts
+	h.actions = map[uint64]*action.Action{}
+	h.read = map[string]bool{}
+	return &h
+}
+
+// historyReader will locate the blocks of revisions within the history. Chunks which have not
+// been exported yet are read from the local directory, the remaining chunks are read from the Source
+type historyReader struct {
+	ctx  context.Context
+	opts *Opts
+
+	// actions are the actions of the chunks which have been read, keyed by position
+	actions map[uint64]*action.Action
+	// read are the filenames of the chunks which have been read
+	read map[string]bool
+	// lastFilename is the last filename listed from the Source
+	lastFilename string
+}
+
+func (h *historyReader) get(r *revisionRecord) (a *action.Action, err error) {
+	position := r.metadata.Position
+	upperBound := r.metadata.Timestamp.UnixNano()
+	// Chunks are removed from the local directory once they have been exported, so the local
+	// directory is read first. This ensures chunks which are still being exported are not read
+	// from the Source before they have been completely written
+	fns := []func(lowerBound, upperBound int64) error{h.readLocal, h.readSource}
+	for _, fn := range fns {
+		if a = h.actions[position]; a != nil {
+			return
+		}
+
+		if err = fn(r.chunkLowerBound, upperBound); err != nil {
+			return
+		}
+	}
+
+	if a = h.actions[position]; a == nil {
+		err = ErrRevisionUnavailable
+	}
+
+	return
+}
+
+// readSource will read the chunks exported to the Source which were created within the bounds.
+// Listing resumes from the last listed filename, so the Source is listed once for all revisions
+func (h *historyReader) readSource(lowerBound, upperBound int64) (err error) {
+	name := h.opts.FullName()
+	if lowerBound > 0 {
+		lower := kiroku.Filename{Name: name, CreatedAt: lowerBound - 1, Filetype: kiroku.TypeChunk}.String()
+		if lower > h.lastFilename {
+			h.lastFilename = lower
+		}
+	}
+
+	for {
+		var filenames []string
+		switch filenames, err = h.opts.Source.GetNextList(h.ctx, name, h.lastFilename, historyListSize); err {
+		case nil:
+		case io.EOF:
+			err = nil
+		default:
+			return
+		}
+
+		if len(filenames) == 0 {
+			return
+		}
+
+		for _, filename := range filenames {
+			parsed, perr := kiroku.ParseFilename(filename)
+			switch {
+			case perr != nil || parsed.Name != name || parsed.Filetype != kiroku.TypeChunk:
+			case parsed.CreatedAt > upperBound:
+				// Chunks are created before the blocks they contain, the chunk is listed again
+				// for the revisions which follow
+				return
+			case !h.read[filename]:
+				var buf bytes.Buffer
+				if err = h.opts.Source.Import(h.ctx, name, filename, &buf); err != nil {
+					err = fmt.Errorf("error importing <%s>: %v", filename, err)
+					return
+				}
+
+				if err = h.readChunk(filename, buf.Bytes()); err != nil {
+					return
+				}
+			}
+
+			h.lastFilename = filename
+		}
+	}
+}
+
+// readLocal will read the chunks within the local directory which were created within the bounds
+func (h *historyReader) readLocal(lowerBound, upperBound int64) (err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(h.opts.Dir); err != nil {
+		return
+	}
+
+	name := h.opts.FullName()
+	for _, entry := range entries {
+		filename := entry.Name()
+		parsed, perr := kiroku.ParseFilename(filename)
+		switch {
+		case perr != nil || parsed.Name != name || parsed.Filetype != kiroku.TypeChunk:
+			continue
+		case parsed.CreatedAt < lowerBound || parsed.CreatedAt > upperBound:
+			continue
+		case h.read[filename]:
+			continue
+		}
+
+		var bs []byte
+		switch bs, err = os.ReadFile(path.Join(h.opts.Dir, filename)); {
+		case os.IsNotExist(err):
+			// Chunk has been exported and removed since the directory was read
+			err = nil
+			continue
+		case err != nil:
+			return
+		}
+
+		if err = h.readChunk(filename, bs); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (h *historyReader) readChunk(filename string, bs []byte) (err error) {
+	r := kiroku.NewReader(bytes.NewReader(bs))
+	if err = r.ForEach(0, func(b kiroku.Block) (err error) {
+		var a action.Action
+		if err = enkodo.NewReader(bytes.NewReader(b)).Decode(&a); err != nil {
+			return
+		}
+
+		if a.Metadata.Position > 0 {
+			h.actions[a.Metadata.Position] = &a
+		}
+
+		return
+	}); err != nil {
+		return fmt.Errorf("error reading <%s>: %v", filename, err)
+	}
+
+	h.read[filename] = true
+	return
+}
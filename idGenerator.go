@@ -0,0 +1,210 @@
+package mojura
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	_ IndexedIDGenerator = &SequentialIDGenerator{}
+	_ IDGenerator        = &ULIDGenerator{}
+	_ IDGenerator        = &UUIDv7Generator{}
+)
+
+// IDGenerator generates the entry IDs for new entries
+type IDGenerator interface {
+	// NewID will return a new entry ID, index is the current index of the database
+	// Note: Errors are returned by New and NewMany as-is, so they can be compared directly
+	NewID(index uint64) (entryID string, err error)
+}
+
+// IndexedIDGenerator is an IDGenerator whose entry IDs are derived from the database index
+type IndexedIDGenerator interface {
+	IDGenerator
+
+	// ParseIndex will return the index an entry ID was generated from
+	ParseIndex(entryID string) (index uint64, ok bool)
+}
+
+// NewSequentialIDGenerator will return a new sequential ID generator
+func NewSequentialIDGenerator(indexLength int) *SequentialIDGenerator {
+	var s SequentialIDGenerator
+	s.indexLength = indexLength
+	s.indexFmt = fmt.Sprintf("%s0%dd", "%", indexLength)
+	s.maxIndex = getMaxIndex(indexLength)
+	return &s
+}
+
+// SequentialIDGenerator generates zero-padded entry IDs from the database index
+// Note: This is the default IDGenerator
+type SequentialIDGenerator struct {
+	indexLength int
+	indexFmt    string
+	// maxIndex is the first index which would exceed the index length, 0 when unbounded
+	maxIndex uint64
+}
+
+// NewID will return a new entry ID
+// Note: Will return ErrIndexLengthExceeded if the index no longer fits within the index length
+func (s *SequentialIDGenerator) NewID(index uint64) (entryID string, err error) {
+	if s.maxIndex > 0 && index >= s.maxIndex {
+		err = ErrIndexLengthExceeded
+		return
+	}
+
+	return fmt.Sprintf(s.indexFmt, index), nil
+}
+
+// IndexLength will return the index length
+func (s *SequentialIDGenerator) IndexLength() (indexLength int) {
+	return s.indexLength
+}
+
+// isNearCapacity will determine if the index has used 90% of the available index length
+func (s *SequentialIDGenerator) isNearCapacity(index uint64) (near bool) {
+	return s.maxIndex > 0 && index >= s.maxIndex/10*9
+}
+
+// ParseIndex will return the index an entry ID was generated from
+func (s *SequentialIDGenerator) ParseIndex(entryID string) (index uint64, ok bool) {
+	var err error
+	if index, err = parseIDAsIndex([]byte(entryID)); err != nil {
+		return
+	}
+
+	return index, true
+}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator generates time-ordered ULIDs (https://github.com/ulid/spec). IDs generated
+// within the same millisecond are monotonically increasing
+type ULIDGenerator struct {
+	mux sync.Mutex
+
+	lastTime uint64
+	entropy  [10]byte
+}
+
+// NewID will return a new entry ID
+func (u *ULIDGenerator) NewID(_ uint64) (entryID string, err error) {
+	u.mux.Lock()
+	defer u.mux.Unlock()
+
+	ms := uint64(time.Now().UnixMilli())
+	switch {
+	case ms > u.lastTime:
+		if _, err = rand.Read(u.entropy[:]); err != nil {
+			return
+		}
+
+		u.lastTime = ms
+	case incrementBytes(u.entropy[:]):
+		// Entropy has overflowed, move to the next millisecond
+		u.lastTime++
+	}
+
+	var bs [16]byte
+	binary.BigEndian.PutUint16(bs[0:2], uint16(u.lastTime>>32))
+	binary.BigEndian.PutUint32(bs[2:6], uint32(u.lastTime))
+	copy(bs[6:], u.entropy[:])
+	return encodeCrockford(bs), nil
+}
+
+// UUIDv7Generator generates time-ordered UUIDv7s (RFC 9562). IDs generated within the same
+// millisecond are monotonically increasing, using a 12-bit counter
+type UUIDv7Generator struct {
+	mux sync.Mutex
+
+	lastTime uint64
+	counter  uint16
+}
+
+// NewID will return a new entry ID
+func (u *UUIDv7Generator) NewID(_ uint64) (entryID string, err error) {
+	u.mux.Lock()
+	defer u.mux.Unlock()
+
+	var bs [16]byte
+	if _, err = rand.Read(bs[:]); err != nil {
+		return
+	}
+
+	ms := uint64(time.Now().UnixMilli())
+	switch {
+	case ms > u.lastTime:
+		u.lastTime = ms
+		// Seed the counter with the lower half of the counter space to leave room for increments
+		u.counter = binary.BigEndian.Uint16(bs[6:8]) & 0x07ff
+	case u.counter == 0x0fff:
+		// Counter has overflowed, move to the next millisecond
+		u.lastTime++
+		u.counter = 0
+	default:
+		u.counter++
+	}
+
+	binary.BigEndian.PutUint16(bs[0:2], uint16(u.lastTime>>32))
+	binary.BigEndian.PutUint32(bs[2:6], uint32(u.lastTime))
+	binary.BigEndian.PutUint16(bs[6:8], 0x7000|u.counter)
+	bs[8] = 0x80 | bs[8]&0x3f
+
+	hexed := hex.EncodeToString(bs[:])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", hexed[0:8], hexed[8:12], hexed[12:16], hexed[16:20], hexed[20:]), nil
+}
+
+// incrementBytes will increment a big-endian number, returning true on overflow
+func incrementBytes(bs []byte) (overflow bool) {
+	for i := len(bs) - 1; i >= 0; i-- {
+		if bs[i]++; bs[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func encodeCrockford(bs [16]byte) string {
+	var (
+		out [26]byte
+		hi  = binary.BigEndian.Uint64(bs[0:8])
+		lo  = binary.BigEndian.Uint64(bs[8:16])
+	)
+
+	// Encode 128 bits as 26 characters of 5 bits, the first character holds the top 3 bits
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(out[:])
+}
+
+// parseIndex will parse the index of an entry ID when the IDGenerator derives IDs from the index
+func parseIndex(g IDGenerator, entryID []byte) (index uint64, ok bool) {
+	ig, ok := g.(IndexedIDGenerator)
+	if !ok {
+		return
+	}
+
+	return ig.ParseIndex(string(entryID))
+}
+
+func getMaxIndex(indexLength int) (max uint64) {
+	if indexLength >= 20 {
+		// All uint64 values fit within 20 digits
+		return
+	}
+
+	max = 1
+	for i := 0; i < indexLength; i++ {
+		max *= 10
+	}
+
+	return
+}
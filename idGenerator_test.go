@@ -0,0 +1,96 @@
+package mojura
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hatchify/errors"
+)
+
+func TestSequentialIDGenerator(t *testing.T) {
+	g := NewSequentialIDGenerator(8)
+	entryID, err := g.NewID(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entryID != "00000042" {
+		t.Fatalf("invalid entry ID, expected <%s> and received <%s>", "00000042", entryID)
+	}
+
+	index, ok := g.ParseIndex(entryID)
+	if !ok || index != 42 {
+		t.Fatalf("invalid index, expected %d and received %d (%v)", 42, index, ok)
+	}
+
+	if _, ok = g.ParseIndex("foobar"); ok {
+		t.Fatal("expected parsing of non-sequential ID to fail")
+	}
+}
+
+func TestULIDGenerator(t *testing.T) {
+	testIDGenerator(t, &ULIDGenerator{}, regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`))
+}
+
+func TestUUIDv7Generator(t *testing.T) {
+	testIDGenerator(t, &UUIDv7Generator{}, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+}
+
+func testIDGenerator(t *testing.T, g IDGenerator, format *regexp.Regexp) {
+	var last string
+	for i := 0; i < 10000; i++ {
+		entryID, err := g.NewID(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !format.MatchString(entryID) {
+			t.Fatalf("invalid entry ID format <%s>", entryID)
+		}
+
+		if entryID <= last {
+			t.Fatalf("entry IDs are not increasing, <%s> was generated after <%s>", entryID, last)
+		}
+
+		last = entryID
+	}
+}
+
+func TestIDGenerator_error(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	opts := MakeOpts("test_id_generator", testDir)
+	opts.IDGenerator = testFailingIDGenerator{}
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.Close() }()
+
+	if _, err = c.New(newTestStruct("user_1", "contact_1", "group_1", "foo")); err != errTestIDGenerator {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", errTestIDGenerator, err)
+	}
+
+	vals := []*testStruct{newTestStruct("user_1", "contact_1", "group_1", "foo")}
+	if _, err = c.NewMany(context.Background(), vals); err != errTestIDGenerator {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", errTestIDGenerator, err)
+	}
+}
+
+const errTestIDGenerator = errors.Error("test ID generator error")
+
+type testFailingIDGenerator struct{}
+
+func (testFailingIDGenerator) NewID(index uint64) (entryID string, err error) {
+	err = errTestIDGenerator
+	return
+}
@@ -0,0 +1,130 @@
+package mojura
+
+import (
+	"github.com/mojura/kiroku"
+	"github.com/mojura/mojura/action"
+)
+
+// OnImportEntries will create an ImportEntriesHandler for Opts.OnImportEntries. The value
+// type of the handler must match the value type of the Mojura instance
+func OnImportEntries[T Value](fn func(ImportEvent[T])) ImportEntriesHandler {
+	return importEntriesFn[T](fn)
+}
+
+// ImportEntriesHandler handles decoded imports, see OnImportEntries
+type ImportEntriesHandler interface {
+	isImportEntriesHandler()
+}
+
+type importEntriesFn[T Value] func(ImportEvent[T])
+
+func (fn importEntriesFn[T]) isImportEntriesHandler() {}
+
+// ImportEvent represents an imported kiroku chunk or snapshot, sent once the import has been committed
+type ImportEvent[T Value] struct {
+	// Type of import, a snapshot contains the full state of the primary
+	Type kiroku.Type
+	// Entries are the imported writes and deletes, in history order
+	Entries []ImportedEntry[T]
+}
+
+// ImportedEntry represents an imported write or delete
+type ImportedEntry[T Value] struct {
+	// Type of action, will be action.TypeWrite or action.TypeDelete
+	Type    action.Type
+	EntryID string
+	// Value of the entry, deletes will contain the deleted value when known
+	Value T
+	// Previous is the value of the entry before the import, only set when HasPrevious is true
+	Previous    T
+	HasPrevious bool
+	// Relationships are the relationship IDs added and removed by the import
+	Relationships RelationshipDelta
+}
+
+// RelationshipDelta represents the relationship IDs added and removed, keyed by relationship key
+type RelationshipDelta struct {
+	Added   map[string][]string
+	Removed map[string][]string
+}
+
+func (r *RelationshipDelta) add(relationshipKey, relationshipID string) {
+	if r.Added == nil {
+		r.Added = map[string][]string{}
+	}
+
+	r.Added[relationshipKey] = append(r.Added[relationshipKey], relationshipID)
+}
+
+func (r *RelationshipDelta) remove(relationshipKey, relationshipID string) {
+	if r.Removed == nil {
+		r.Removed = map[string][]string{}
+	}
+
+	r.Removed[relationshipKey] = append(r.Removed[relationshipKey], relationshipID)
+}
+
+func (m *Mojura[T]) getRelationshipDelta(old, current Relationships) (delta RelationshipDelta) {
+	for i, relationship := range m.relationships {
+		relationshipKey := string(relationship)
+
+		var oldIDs, newIDs Relationship
+		if i < len(old) {
+			oldIDs = old[i]
+		}
+
+		if i < len(current) {
+			newIDs = current[i]
+		}
+
+		for _, relationshipID := range newIDs {
+			if !oldIDs.Has(relationshipID) {
+				delta.add(relationshipKey, relationshipID)
+			}
+		}
+
+		for _, relationshipID := range oldIDs {
+			if !newIDs.Has(relationshipID) {
+				delta.remove(relationshipKey, relationshipID)
+			}
+		}
+	}
+
+	return
+}
+
+// getImportedEntry will return an imported entry containing the value of the entry before
+// the import, along with it's indexed relationships
+func (t *Transaction[T]) getImportedEntry(actionType action.Type, entryID []byte) (e ImportedEntry[T], indexed Relationships, err error) {
+	e.Type = actionType
+	e.EntryID = string(entryID)
+
+	switch e.Previous, err = t.get(entryID); err {
+	case nil:
+	case ErrEntryNotFound:
+		err = nil
+		return
+
+	default:
+		return
+	}
+
+	var val T
+	if val, err = t.getIndexed(entryID, e.Previous); err != nil {
+		return
+	}
+
+	e.HasPrevious = true
+	indexed = t.m.getRelationships(val)
+	return
+}
+
+func (t *Transaction[T]) addImportedEntry(e ImportedEntry[T], old Relationships, val T) {
+	var rs Relationships
+	if e.Value = val; e.Type == action.TypeWrite {
+		rs = t.m.getRelationships(val)
+	}
+
+	e.Relationships = t.m.getRelationshipDelta(old, rs)
+	t.importEvent.Entries = append(t.importEvent.Entries, e)
+}
@@ -1,5 +1,10 @@
 package mojura
 
+import (
+	"context"
+	"iter"
+)
+
 func getIDIteratorFunc(c IDCursor, reverse bool) (fn idIteratorFn) {
 	if !reverse {
 		// Current request is a forward-direction cursor, return cursor.Next (incrementing)
@@ -23,3 +28,232 @@ func getIteratorFunc[T Value](c Cursor[T], reverse bool) (fn iteratorFn[T]) {
 }
 
 type iteratorFn[T Value] func() (val T, err error)
+
+// iterationPageSize is the number of entries read within each read transaction of an iterator
+const iterationPageSize = 64
+
+func (t *Transaction[T]) iterate(o *FilteringOpts, yield func(entryID string, val T) bool) (err error) {
+	if o == nil {
+		o = defaultFilteringOpts
+	}
+
+	var c Cursor[T]
+	if c, err = t.cursor(o.Filters); err != nil {
+		return
+	}
+	defer c.teardown()
+
+	iterator := getIteratorFunc(c, o.Reverse)
+
+	var val T
+	for val, err = getFirst(c, o.LastID, o.Reverse); err == nil; val, err = iterator() {
+		if !yield(val.GetID(), val) {
+			return nil
+		}
+
+		if err = t.cc.isDone(); err != nil {
+			return
+		}
+	}
+
+	if err == Break {
+		err = nil
+	}
+
+	return
+}
+
+func (t *Transaction[T]) iterateIDs(o *FilteringOpts, yield func(entryID string) bool) (err error) {
+	if o == nil {
+		o = defaultFilteringOpts
+	}
+
+	var c IDCursor
+	if c, err = t.idCursor(o.Filters); err != nil {
+		return
+	}
+	defer c.teardown()
+
+	iterator := getIDIteratorFunc(c, o.Reverse)
+
+	var entryID string
+	for entryID, err = getFirstID(c, o.LastID, o.Reverse); err == nil; entryID, err = iterator() {
+		if !yield(entryID) {
+			return nil
+		}
+
+		if err = t.cc.isDone(); err != nil {
+			return
+		}
+	}
+
+	if err == Break {
+		err = nil
+	}
+
+	return
+}
+
+// getIDsPage will return up to a page of the entry IDs which follow the last ID
+func (t *Transaction[T]) getIDsPage(o *FilteringOpts, lastID string) (entryIDs []string, err error) {
+	var c IDCursor
+	if c, err = t.idCursor(o.Filters); err != nil {
+		return
+	}
+	defer c.teardown()
+
+	iterator := getIDIteratorFunc(c, o.Reverse)
+
+	var entryID string
+	for entryID, err = getIDAfter(c, lastID, o.Reverse); err == nil; entryID, err = iterator() {
+		if entryIDs = append(entryIDs, entryID); len(entryIDs) == iterationPageSize {
+			return
+		}
+	}
+
+	if err == Break {
+		err = nil
+	}
+
+	return
+}
+
+// getPage will return up to a page of the entries which follow the last ID
+func (t *Transaction[T]) getPage(o *FilteringOpts, lastID string) (page []T, err error) {
+	var entryIDs []string
+	if entryIDs, err = t.getIDsPage(o, lastID); err != nil {
+		return
+	}
+
+	page = make([]T, 0, len(entryIDs))
+	for _, entryID := range entryIDs {
+		var val T
+		if val, err = t.get([]byte(entryID)); err != nil {
+			return
+		}
+
+		page = append(page, val)
+	}
+
+	return
+}
+
+// iteratePages will iterate through the entries which match the filtering options a page at a
+// time. Each page is read within it's own read transaction, which is released before the
+// entries of the page are yielded
+func (m *Mojura[T]) iteratePages(ctx context.Context, o *FilteringOpts, yield func(entryID string, val T) bool) (err error) {
+	if o == nil {
+		o = defaultFilteringOpts
+	}
+
+	lastID := o.LastID
+	for {
+		var page []T
+		if err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
+			page, err = txn.getPage(o, lastID)
+			return
+		}); err != nil {
+			return
+		}
+
+		for _, val := range page {
+			if !yield(val.GetID(), val) {
+				return
+			}
+
+			if err = ctx.Err(); err != nil {
+				return
+			}
+		}
+
+		if len(page) < iterationPageSize {
+			return
+		}
+
+		lastID = page[len(page)-1].GetID()
+	}
+}
+
+// iterateIDPages will iterate through the entry IDs which match the filtering options a page at
+// a time. Each page is read within it's own read transaction, which is released before the
+// entry IDs of the page are yielded
+func (m *Mojura[T]) iterateIDPages(ctx context.Context, o *FilteringOpts, yield func(entryID string) bool) (err error) {
+	if o == nil {
+		o = defaultFilteringOpts
+	}
+
+	lastID := o.LastID
+	for {
+		var entryIDs []string
+		if err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
+			entryIDs, err = txn.getIDsPage(o, lastID)
+			return
+		}); err != nil {
+			return
+		}
+
+		for _, entryID := range entryIDs {
+			if !yield(entryID) {
+				return
+			}
+
+			if err = ctx.Err(); err != nil {
+				return
+			}
+		}
+
+		if len(entryIDs) < iterationPageSize {
+			return
+		}
+
+		lastID = entryIDs[len(entryIDs)-1]
+	}
+}
+
+// getIDAfter will return the first entry ID which follows the last ID. Unlike getFirstID, the
+// entry which follows is not skipped when the last ID was removed between pages
+func getIDAfter(c IDCursor, lastID string, reverse bool) (entryID string, err error) {
+	if len(lastID) == 0 {
+		return getFirstID(c, lastID, reverse)
+	}
+
+	if !reverse {
+		if entryID, err = c.Seek(lastID); err != nil || entryID > lastID {
+			return
+		}
+
+		return c.Next()
+	}
+
+	switch entryID, err = c.SeekReverse(lastID); {
+	case err == Break:
+		// No entries are at or after the last ID
+		return c.Last()
+	case err != nil:
+		return
+	case entryID < lastID:
+		return
+	}
+
+	return c.Prev()
+}
+
+func makeSeq2[T Value](errp *error, fn func(yield func(string, T) bool) error) iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		setIterationError(errp, fn(yield))
+	}
+}
+
+func makeSeq(errp *error, fn func(yield func(string) bool) error) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		setIterationError(errp, fn(yield))
+	}
+}
+
+func setIterationError(errp *error, err error) {
+	if errp == nil {
+		return
+	}
+
+	*errp = err
+}
@@ -0,0 +1,47 @@
+package mojura
+
+// Lookuper is an optional interface for Values which provide unique lookups
+type Lookuper interface {
+	GetLookups() Lookups
+}
+
+// Lookups are unique lookup IDs for an Entry, keyed by lookup key
+// (e.g. {"email": "user@example.com"})
+type Lookups map[string]string
+
+func (l Lookups) delta(old Lookups, onSet, onRemove LookupFn) (err error) {
+	// Remove old lookups first so an entry can swap lookup IDs with another lookup key
+	for lookupKey, lookupID := range old {
+		if len(lookupID) == 0 || l[lookupKey] == lookupID {
+			continue
+		}
+
+		if err = onRemove([]byte(lookupKey), []byte(lookupID)); err != nil {
+			return
+		}
+	}
+
+	for lookupKey, lookupID := range l {
+		if len(lookupID) == 0 || old[lookupKey] == lookupID {
+			continue
+		}
+
+		if err = onSet([]byte(lookupKey), []byte(lookupID)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// LookupFn is called for lookup comparison funcs
+type LookupFn func(lookupKey, lookupID []byte) error
+
+func getLookups[T Value](val T) (l Lookups) {
+	lookuper, ok := any(val).(Lookuper)
+	if !ok {
+		return
+	}
+
+	return lookuper.GetLookups()
+}
@@ -4,4 +4,16 @@ package mojura
 type metadata struct {
 	// CurrentIndex would be the current index count
 	CurrentIndex uint64 `json:"currentIndex"`
+	// EntryCount is the total number of entries
+	EntryCount int64 `json:"entryCount"`
+	// CountsIndexed notes if the entry and relationship counts have been built
+	CountsIndexed bool `json:"countsIndexed"`
+	// Relationships are the relationship keys the database was last opened with
+	Relationships []string `json:"relationships"`
+	// SchemaVersion is the schema version of entries without a schema version record
+	SchemaVersion int64 `json:"schemaVersion"`
+	// IndexLength is the index length of sequential entry IDs, set once entries have been rekeyed
+	IndexLength int `json:"indexLength,omitempty"`
+	// Position is the position of the last committed change
+	Position uint64 `json:"position,omitempty"`
 }
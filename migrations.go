@@ -0,0 +1,213 @@
+package mojura
+
+import (
+	"fmt"
+
+	"github.com/mojura/backend"
+)
+
+// Upgrader will upgrade the encoded bytes of an entry by a single schema version
+type Upgrader func(old []byte) ([]byte, error)
+
+// getCurrentSchemaVersion will return the schema version of newly written entries
+func (t *Transaction[T]) getCurrentSchemaVersion() (version int64) {
+	return int64(len(t.m.opts.Upgraders))
+}
+
+func (t *Transaction[T]) getSchemaVersionsBucket() (bkt backend.Bucket, err error) {
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	if bkt = t.txn.GetBucket(schemaVersionsBktKey); bkt == nil {
+		err = ErrNotInitialized
+		return
+	}
+
+	return
+}
+
+// getSchemaVersion will return the schema version of an entry. Entries without a schema
+// version record are at the database schema version
+func (t *Transaction[T]) getSchemaVersion(entryID []byte) (version int64, err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getSchemaVersionsBucket(); err != nil {
+		return
+	}
+
+	if bs := bkt.Get(entryID); len(bs) > 0 {
+		version = decodeCount(bs)
+		return
+	}
+
+	if err = t.ensureMeta(); err != nil {
+		return
+	}
+
+	version = t.meta.SchemaVersion
+	return
+}
+
+// setSchemaVersion will record the current schema version for an entry which has been written
+func (t *Transaction[T]) setSchemaVersion(entryID []byte) (err error) {
+	current := t.getCurrentSchemaVersion()
+	if current == 0 && t.meta.SchemaVersion == 0 {
+		// Upgraders are not in use, no need to track schema versions
+		return
+	}
+
+	if current == t.meta.SchemaVersion {
+		// Entry matches the database schema version
+		return t.unsetSchemaVersion(entryID)
+	}
+
+	var bkt backend.Bucket
+	if bkt, err = t.getSchemaVersionsBucket(); err != nil {
+		return
+	}
+
+	return bkt.Put(entryID, encodeCount(current))
+}
+
+func (t *Transaction[T]) unsetSchemaVersion(entryID []byte) (err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getSchemaVersionsBucket(); err != nil {
+		return
+	}
+
+	if !hasKey(bkt, entryID) {
+		return
+	}
+
+	return bkt.Delete(entryID)
+}
+
+// upgrade will upgrade the encoded bytes of an entry to the current schema version
+func (t *Transaction[T]) upgrade(entryID, bs []byte) (upgraded []byte, err error) {
+	upgraded = bs
+	current := t.getCurrentSchemaVersion()
+	if current == 0 {
+		return
+	}
+
+	var version int64
+	if version, err = t.getSchemaVersion(entryID); err != nil {
+		return
+	}
+
+	return t.upgradeFrom(entryID, bs, version)
+}
+
+// upgradeFrom will upgrade encoded bytes from a schema version to the current schema version
+func (t *Transaction[T]) upgradeFrom(entryID, bs []byte, version int64) (upgraded []byte, err error) {
+	return t.m.upgradeFrom(entryID, bs, version)
+}
+
+// upgradeFrom will upgrade encoded bytes from a schema version to the current schema version,
+// without requiring a transaction
+func (m *Mojura[T]) upgradeFrom(entryID, bs []byte, version int64) (upgraded []byte, err error) {
+	upgraded = bs
+	current := int64(len(m.opts.Upgraders))
+	for ; version < current; version++ {
+		if upgraded, err = m.opts.Upgraders[version](upgraded); err != nil {
+			err = fmt.Errorf("error upgrading <%s> from schema version %d: %v", entryID, version, err)
+			return
+		}
+	}
+
+	return
+}
+
+func (t *Transaction[T]) newValueFromBytes(entryID, bs []byte) (val T, err error) {
+	if bs, err = t.upgrade(entryID, bs); err != nil {
+		return
+	}
+
+	return t.m.newValueFromBytes(bs)
+}
+
+// getIndexed will return the value an entry's relationships and lookups were indexed with.
+// Entries behind the current schema version were indexed prior to being upgraded
+func (t *Transaction[T]) getIndexed(entryID []byte, val T) (indexed T, err error) {
+	current := t.getCurrentSchemaVersion()
+	if current == 0 {
+		return val, nil
+	}
+
+	var version int64
+	if version, err = t.getSchemaVersion(entryID); err != nil || version == current {
+		return val, err
+	}
+
+	var bs []byte
+	if bs, err = t.getBytes(entryID); err != nil {
+		return
+	}
+
+	return t.m.newValueFromBytes(bs)
+}
+
+// migrate will rewrite all of the entries which are behind the current schema version
+func (t *Transaction[T]) migrate() (n int64, err error) {
+	if err = t.ensureMeta(); err != nil {
+		return
+	}
+
+	current := t.getCurrentSchemaVersion()
+	if t.meta.SchemaVersion == current {
+		var bkt backend.Bucket
+		if bkt, err = t.getSchemaVersionsBucket(); err != nil {
+			return
+		}
+
+		if !hasEntries(bkt) {
+			// All entries are at the current schema version
+			return
+		}
+	}
+
+	var entriesBkt backend.Bucket
+	if entriesBkt, err = t.getEntriesBucket(); err != nil {
+		return
+	}
+
+	// Collect the outdated entry IDs ahead of writing, as writing to the bucket during
+	// iteration would invalidate the cursor
+	var entryIDs []string
+	cur := entriesBkt.Cursor()
+	for entryID, _ := cur.First(); entryID != nil; entryID, _ = cur.Next() {
+		var version int64
+		if version, err = t.getSchemaVersion(entryID); err != nil {
+			return
+		}
+
+		if version < current {
+			entryIDs = append(entryIDs, string(entryID))
+		}
+	}
+
+	for _, entryID := range entryIDs {
+		var val T
+		if val, err = t.get([]byte(entryID)); err != nil {
+			return
+		}
+
+		// The entry representation has changed, so the version is incremented
+		setVersion(val, getVersion(val)+1)
+		if err = t.insertEntry([]byte(entryID), val); err != nil {
+			return
+		}
+
+		n++
+	}
+
+	// All entries are now at the current schema version
+	t.meta.SchemaVersion = current
+	t.metaUpdated = true
+	if err = t.txn.DeleteBucket(schemaVersionsBktKey); err != nil {
+		return
+	}
+
+	_, err = t.txn.GetOrCreateBucket(schemaVersionsBktKey)
+	return
+}
@@ -3,8 +3,13 @@ package mojura
 import (
 	"context"
 	"fmt"
+	"iter"
+	"os"
 	"path"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gdbu/scribe"
 	"github.com/gdbu/stopwatch"
@@ -23,6 +28,8 @@ const (
 	ErrRelationshipNotFound = errors.Error("relationship was not found")
 	// ErrEntryNotFound is returned when an entry is not available for the given ID
 	ErrEntryNotFound = errors.Error("entry was not found")
+	// ErrEntryExists is returned when an entry already exists for the given ID
+	ErrEntryExists = errors.Error("entry already exists")
 	// ErrEndOfEntries is returned when a cursor has reached the end of entries
 	ErrEndOfEntries = errors.Error("end of entries")
 	// ErrInvalidNumberOfRelationships is returned when an invalid number of relationships is provided in a New call
@@ -41,6 +48,46 @@ const (
 	ErrEmptyEntryID = errors.Error("invalid entry ID, cannot be empty")
 	// ErrMirrorCannotPerformWriteActions is returned when write actions are called on a mirror
 	ErrMirrorCannotPerformWriteActions = errors.Error("mirrors cannot perform write actions")
+	// ErrLookupNotFound is returned when a lookup is not available for the given lookup key and ID
+	ErrLookupNotFound = errors.Error("lookup was not found")
+	// ErrLookupExists is returned when a lookup is already set for another entry
+	ErrLookupExists = errors.Error("lookup already exists for another entry")
+	// ErrEmptyLookup is returned when a lookup key or lookup ID is empty
+	ErrEmptyLookup = errors.Error("invalid lookup, key and ID cannot be empty")
+	// ErrUniqueConstraintViolation is returned when a unique relationship ID is already held by another entry
+	ErrUniqueConstraintViolation = errors.Error("unique constraint violation")
+	// ErrVersionConflict is returned when the version of an entry does not match the expected version
+	ErrVersionConflict = errors.Error("version conflict")
+	// ErrVersionsNotSupported is returned when a version check is made for a Value which does not implement Versioner
+	ErrVersionsNotSupported = errors.Error("versions are not supported, Value does not implement Versioner")
+	// ErrIndexLengthExceeded is returned when a sequential entry ID would exceed the index length
+	ErrIndexLengthExceeded = errors.Error("index has exceeded the index length, use Rekey to widen the index length")
+	// ErrInvalidIndexLength is returned when a Rekey index length does not widen the current index length
+	ErrInvalidIndexLength = errors.Error("invalid index length, must be greater than the current index length")
+	// ErrRekeyNotSupported is returned when a Rekey is attempted without a SequentialIDGenerator
+	ErrRekeyNotSupported = errors.Error("rekey is only supported for the SequentialIDGenerator")
+	// ErrPositionUnavailable is returned when a subscription cannot be resumed from a position
+	ErrPositionUnavailable = errors.Error("position is not available within the change backlog")
+	// ErrInvalidImportEntriesHandler is returned when the value type of Opts.OnImportEntries does not match
+	ErrInvalidImportEntriesHandler = errors.Error("invalid import entries handler, value type does not match")
+	// ErrAlreadyPrimary is returned when promoting an instance which is already a primary
+	ErrAlreadyPrimary = errors.Error("instance is already a primary")
+	// ErrAlreadyMirror is returned when demoting an instance which is already a mirror
+	ErrAlreadyMirror = errors.Error("instance is already a mirror")
+	// ErrSourceNotSet is returned when a history Source is required and has not been set
+	ErrSourceNotSet = errors.Error("history source has not been set")
+	// ErrRestoreTargetExists is returned when a restore target directory already contains a database
+	ErrRestoreTargetExists = errors.Error("restore target already contains a database")
+	// ErrHistoryNotIndexed is returned when history is requested without Opts.IndexHistory being set
+	ErrHistoryNotIndexed = errors.Error("history is not indexed, Opts.IndexHistory must be set")
+	// ErrInvalidRevision is returned when a revision record is malformed
+	ErrInvalidRevision = errors.Error("invalid revision record")
+	// ErrRevisionUnavailable is returned when the block of a revision cannot be found within the history
+	ErrRevisionUnavailable = errors.Error("revision is not available within the history")
+	// ErrSoftDeleteNotEnabled is returned when restoring or purging without Opts.SoftDelete being set
+	ErrSoftDeleteNotEnabled = errors.Error("soft delete is not enabled, Opts.SoftDelete must be set")
+	// ErrInvalidTombstone is returned when a tombstone record is malformed
+	ErrInvalidTombstone = errors.Error("invalid tombstone record")
 	// Break is a non-error which will cause a ForEach loop to break early
 	Break = errors.Error("break!")
 )
@@ -49,7 +96,16 @@ var (
 	entriesBktKey       = []byte("entries")
 	relationshipsBktKey = []byte("relationships")
 	lookupsBktKey       = []byte("lookups")
+	// manualLookupsBktKey stores the lookups set with SetLookup, keyed by entry ID
+	manualLookupsBktKey = []byte("manualLookups")
 	metaBktKey          = []byte("meta")
+	countsBktKey        = []byte("counts")
+	// schemaVersionsBktKey stores the schema versions of entries which differ from the database schema version
+	schemaVersionsBktKey = []byte("schemaVersions")
+	// historyBktKey stores the revisions of entries, keyed by entry ID and revision sequence
+	historyBktKey = []byte("history")
+	// tombstonesBktKey stores soft-deleted entries, keyed by entry ID
+	tombstonesBktKey = []byte("tombstones")
 )
 
 // New will return a new instance of Mojura
@@ -72,18 +128,54 @@ func makeMojura[T Value](opts Opts, relationships []string) (m Mojura[T], err er
 	}
 
 	m.make = makeType[T]()
-	t := m.make()
-	if len(t.GetRelationships()) != len(relationships) {
-		err = ErrInvalidNumberOfRelationships
+	if m.relationshipFields, err = getRelationshipFields(reflect.TypeFor[T]()); err != nil {
 		return
 	}
 
+	switch {
+	case len(m.relationshipFields) == 0:
+		t := m.make()
+		if len(t.GetRelationships()) != len(relationships) {
+			err = ErrInvalidNumberOfRelationships
+			return
+		}
+
+	case len(relationships) == 0:
+		// Relationships are derived from the tagged fields
+		relationships = getRelationshipKeys(m.relationshipFields)
+
+	default:
+		if err = validateRelationshipFields(m.relationshipFields, relationships); err != nil {
+			return
+		}
+	}
+
+	if opts.OnImportEntries != nil {
+		var ok bool
+		if m.onImportEntries, ok = opts.OnImportEntries.(importEntriesFn[T]); !ok {
+			err = ErrInvalidImportEntriesHandler
+			return
+		}
+	}
+
 	m.out = scribe.New(fmt.Sprintf("Mojura (%s)", opts.Name))
 	opts.OnLog = m.out.Notification
-	opts.OnError = func(err error) { m.out.Error(err.Error()) }
+	opts.OnError = func(err error) {
+		m.rs.onError(err)
+		m.out.Error(err.Error())
+	}
 	m.opts = &opts
-	m.indexFmt = fmt.Sprintf("%s0%dd", "%", opts.IndexLength)
+	m.mirror.Store(opts.IsMirror)
+	var readHistory readHistoryFn[T]
+	if opts.Source != nil {
+		readHistory = m.getHistoryChanges
+	}
 
+	m.feed = newChangeFeed(opts.ChangeBacklogSize, opts.SubscriptionBufferSize, readHistory)
+	m.rs = newReplicationState()
+	m.idGenerator = opts.IDGenerator
+
+	relationships, m.uniqueRelationships = parseRelationships(relationships)
 	if err = m.init(relationships); err != nil {
 		return
 	}
@@ -95,6 +187,8 @@ func makeMojura[T Value](opts Opts, relationships []string) (m Mojura[T], err er
 type Mojura[T Value] struct {
 	// Closed state mutex
 	mux sync.RWMutex
+	// roleMux ensures promotions and demotions do not run concurrently
+	roleMux sync.Mutex
 
 	db  backend.Backend
 	out *scribe.Scribe
@@ -104,11 +198,30 @@ type Mojura[T Value] struct {
 
 	p *kiroku.Producer
 	c closer
+	// mirror is the current role of the instance. It starts as Opts.IsMirror and is switched
+	// by Promote and Demote once the producer or consumer has been swapped
+	mirror atomic.Bool
+
+	feed *changeFeed[T]
+	// onImportEntries is set when Opts.OnImportEntries has been provided
+	onImportEntries importEntriesFn[T]
 
-	opts     *Opts
-	indexFmt string
+	rs *replicationState
+
+	// idMux guards the ID generator, which is switched once a rekey has been committed
+	idMux       sync.RWMutex
+	idGenerator IDGenerator
+
+	// viewDir is the temporary directory of a point-in-time view, removed on close
+	viewDir string
+
+	opts *Opts
 
 	relationships [][]byte
+	// uniqueRelationships notes which relationships are unique, by relationship index
+	uniqueRelationships []bool
+	// relationshipFields are set when relationships are derived from struct tags
+	relationshipFields []relationshipField
 
 	// Closed state
 	closed bool
@@ -132,10 +245,30 @@ func (m *Mojura[T]) init(relationships []string) (err error) {
 			return
 		}
 
+		if _, err = txn.GetOrCreateBucket(manualLookupsBktKey); err != nil {
+			return
+		}
+
 		if _, err = txn.GetOrCreateBucket(metaBktKey); err != nil {
 			return
 		}
 
+		if _, err = txn.GetOrCreateBucket(countsBktKey); err != nil {
+			return
+		}
+
+		if _, err = txn.GetOrCreateBucket(schemaVersionsBktKey); err != nil {
+			return
+		}
+
+		if _, err = txn.GetOrCreateBucket(historyBktKey); err != nil {
+			return
+		}
+
+		if _, err = txn.GetOrCreateBucket(tombstonesBktKey); err != nil {
+			return
+		}
+
 		var relationshipsBkt backend.Bucket
 		if relationshipsBkt, err = txn.GetOrCreateBucket(relationshipsBktKey); err != nil {
 			return
@@ -152,7 +285,27 @@ func (m *Mojura[T]) init(relationships []string) (err error) {
 		return
 	}
 
-	if !m.opts.IsMirror {
+	if err = m.initSchema(); err != nil {
+		err = fmt.Errorf("error initializing schema: %v", err)
+		return
+	}
+
+	if err = m.initCounts(); err != nil {
+		err = fmt.Errorf("error initializing counts: %v", err)
+		return
+	}
+
+	if err = m.initIndexLength(); err != nil {
+		err = fmt.Errorf("error initializing index length: %v", err)
+		return
+	}
+
+	if err = m.initPosition(); err != nil {
+		err = fmt.Errorf("error initializing position: %v", err)
+		return
+	}
+
+	if !m.mirror.Load() {
 		err = m.primaryInitialization()
 	} else {
 		err = m.mirrorInitialization()
@@ -170,10 +323,30 @@ func (m *Mojura[T]) initBuckets(txn backend.Transaction) (err error) {
 		return
 	}
 
+	if _, err = txn.GetOrCreateBucket(manualLookupsBktKey); err != nil {
+		return
+	}
+
 	if _, err = txn.GetOrCreateBucket(metaBktKey); err != nil {
 		return
 	}
 
+	if _, err = txn.GetOrCreateBucket(countsBktKey); err != nil {
+		return
+	}
+
+	if _, err = txn.GetOrCreateBucket(schemaVersionsBktKey); err != nil {
+		return
+	}
+
+	if _, err = txn.GetOrCreateBucket(historyBktKey); err != nil {
+		return
+	}
+
+	if _, err = txn.GetOrCreateBucket(tombstonesBktKey); err != nil {
+		return
+	}
+
 	return m.initRelationshipsBuckets(txn)
 }
 
@@ -267,16 +440,24 @@ func (m *Mojura[T]) dumpHistory(txn *Transaction[T]) (n int64, err error) {
 		return
 	}
 
+	// Entries are upgraded to the current schema version before being written to the history
+	upgrade := txn.upgrade
+	g := txn.getIDGenerator()
+
 	var lastIndex uint64
 	if err = m.p.Transaction(func(txn *kiroku.Transaction) (err error) {
 		cur := bkt.Cursor()
 		aw := action.MakeWriter(txn)
 		for key, value := cur.First(); len(key) > 0; key, value = cur.Next() {
+			if value, err = upgrade(key, value); err != nil {
+				return
+			}
+
 			if err = aw.Write(key, value); err != nil {
 				return
 			}
 
-			if parsed, err := parseIDAsIndex(key); err == nil {
+			if parsed, ok := parseIndex(g, key); ok {
 				lastIndex = parsed
 			}
 
@@ -292,11 +473,40 @@ func (m *Mojura[T]) dumpHistory(txn *Transaction[T]) (n int64, err error) {
 	return
 }
 
+func (m *Mojura[T]) initCounts() (err error) {
+	return m.importTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+		if txn.meta.CountsIndexed {
+			return
+		}
+
+		var hasEntries bool
+		if hasEntries, err = m.hasEntries(txn); err != nil {
+			return
+		}
+
+		if hasEntries {
+			m.out.Notification("Found populated database without counts, building counts from database entries")
+		}
+
+		return txn.indexCounts()
+	})
+}
+
+// purge will remove all entries and indexes, snapshots contain the full state so entries which
+// are not within a snapshot must not remain
 func (m *Mojura[T]) purge(txn backend.Transaction) (err error) {
+	if err = txn.DeleteBucket(entriesBktKey); err != nil {
+		return
+	}
+
 	if err = txn.DeleteBucket(lookupsBktKey); err != nil {
 		return
 	}
 
+	if err = txn.DeleteBucket(manualLookupsBktKey); err != nil {
+		return
+	}
+
 	if err = txn.DeleteBucket(metaBktKey); err != nil {
 		return
 	}
@@ -305,9 +515,37 @@ func (m *Mojura[T]) purge(txn backend.Transaction) (err error) {
 		return
 	}
 
+	if err = txn.DeleteBucket(countsBktKey); err != nil {
+		return
+	}
+
+	if err = txn.DeleteBucket(schemaVersionsBktKey); err != nil {
+		return
+	}
+
+	// Tombstones are included within snapshots
+	if err = txn.DeleteBucket(tombstonesBktKey); err != nil {
+		return
+	}
+
+	// Revisions are not included within snapshots, the revisions of purged entries must not remain
+	if err = txn.DeleteBucket(historyBktKey); err != nil {
+		return
+	}
+
 	return m.initBuckets(txn)
 }
 
+// getRelationships will get the relationships of a value, using the tagged relationship
+// fields when available
+func (m *Mojura[T]) getRelationships(val T) (r Relationships) {
+	if len(m.relationshipFields) == 0 {
+		return val.GetRelationships()
+	}
+
+	return getTaggedRelationships(m.relationshipFields, val)
+}
+
 func (m *Mojura[T]) marshal(val interface{}) (bs []byte, err error) {
 	return m.opts.Encoder.Marshal(val)
 }
@@ -322,12 +560,36 @@ func (m *Mojura[T]) newValueFromBytes(bs []byte) (val T, err error) {
 }
 
 func (m *Mojura[T]) onImport(t kiroku.Type, r *kiroku.Reader) (err error) {
+	var event *ImportEvent[T]
+	if m.onImportEntries != nil {
+		event = &ImportEvent[T]{Type: t}
+	}
+
+	var (
+		blocks   int
+		position uint64
+	)
+
 	if err = m.importTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
-		return m.importReader(txn, t, r)
+		txn.importEvent = event
+		if blocks, err = m.importReader(txn, t, r); err != nil {
+			return
+		}
+
+		position = txn.meta.Position
+		return
 	}); err != nil {
+		m.rs.onError(err)
 		return
 	}
 
+	createdAt, _ := getChunkTimestamp(r)
+	m.rs.onImport(t, createdAt, position, blocks)
+
+	if event != nil {
+		m.onImportEntries(*event)
+	}
+
 	if m.opts.OnImport == nil {
 		return
 	}
@@ -337,9 +599,11 @@ func (m *Mojura[T]) onImport(t kiroku.Type, r *kiroku.Reader) (err error) {
 	return
 }
 
-func (m *Mojura[T]) importReader(txn *Transaction[T], t kiroku.Type, r *kiroku.Reader) (err error) {
+func (m *Mojura[T]) importReader(txn *Transaction[T], t kiroku.Type, r *kiroku.Reader) (count int, err error) {
 	var sw stopwatch.Stopwatch
 	sw.Start()
+	// Revisions are located within the history by the chunk they were imported from
+	txn.chunkCreatedAt, _ = getChunkTimestamp(r)
 	if t == kiroku.TypeSnapshot {
 		// Snapshot occurred, purge DB and perform a full sync
 		if err = m.purge(txn.txn); err != nil {
@@ -347,7 +611,6 @@ func (m *Mojura[T]) importReader(txn *Transaction[T], t kiroku.Type, r *kiroku.R
 		}
 	}
 
-	var count int
 	// Iterate through all entries from a given point within Reader
 	if err = r.ForEach(0, func(b kiroku.Block) (err error) {
 		count++
@@ -357,21 +620,54 @@ func (m *Mojura[T]) importReader(txn *Transaction[T], t kiroku.Type, r *kiroku.R
 		return
 	}
 
+	if t == kiroku.TypeSnapshot {
+		// Counts were purged along with the relationships, rebuild them from the synced state
+		if err = txn.indexCounts(); err != nil {
+			err = fmt.Errorf("Mojura.importReader(): error indexing counts: %v", err)
+			return
+		}
+	}
+
 	m.out.Successf("Successfully processed %d blocks in %v", count, sw.Stop())
 	return
 }
 
-func (m *Mojura[T]) transaction(fn func(backend.Transaction, *kiroku.Transaction) (Transaction[T], error)) (err error) {
-	err = m.db.Transaction(func(txn backend.Transaction) (err error) {
+func (m *Mojura[T]) transaction(fn func(backend.Transaction, *kiroku.Transaction, int64) (Transaction[T], error)) (err error) {
+	m.feed.commitMux.Lock()
+	var (
+		changes []Change[T]
+		next    *SequentialIDGenerator
+	)
+
+	if err = m.db.Transaction(func(txn backend.Transaction) (err error) {
 		var t Transaction[T]
+		// The chunk of the transaction is created after the producer transaction has started
+		startedAt := time.Now().UnixNano()
 		err = m.p.Transaction(func(ktxn *kiroku.Transaction) (err error) {
-			t, err = fn(txn, ktxn)
+			t, err = fn(txn, ktxn, startedAt)
 			return
 		})
+		changes = t.changes
+		next = t.nextIDGenerator
 		defer t.teardown()
 		return
-	})
+	}); err != nil {
+		m.feed.commitMux.Unlock()
+		return
+	}
+
+	// The ID generator is switched before the commit lock is released, so the next
+	// transaction uses the generator of the committed rekey
+	m.switchIDGenerator(next)
 
+	// The publish lock is acquired before the commit lock is released, so changes are
+	// published in commit order while the next transaction is able to begin
+	m.feed.publishMux.Lock()
+	defer m.feed.publishMux.Unlock()
+	m.feed.commitMux.Unlock()
+
+	m.decodeChanges(changes)
+	m.feed.publish(changes)
 	return
 }
 
@@ -411,13 +707,18 @@ func (m *Mojura[T]) runTransaction(ctx context.Context, txn backend.Transaction,
 }
 
 func (m *Mojura[T]) importTransaction(ctx context.Context, fn func(*Transaction[T]) error) (err error) {
-	err = m.db.Transaction(func(txn backend.Transaction) (err error) {
+	var next *SequentialIDGenerator
+	if err = m.db.Transaction(func(txn backend.Transaction) (err error) {
 		var t Transaction[T]
 		t, err = m.runTransaction(ctx, txn, nopBW, fn)
+		next = t.nextIDGenerator
 		defer t.teardown()
 		return
-	})
+	}); err != nil {
+		return
+	}
 
+	m.switchIDGenerator(next)
 	return
 }
 
@@ -439,8 +740,31 @@ func (m *Mojura[T]) copyEntries(txn *Transaction[T]) (err error) {
 	}
 
 	writeFn := func(ss *kiroku.Snapshot) (err error) {
+		if err = txn.copyPosition(ss); err != nil {
+			return
+		}
+
 		aw := action.MakeWriter(ss)
-		return bkt.ForEach(aw.Write)
+		if err = bkt.ForEach(func(key, value []byte) (err error) {
+			// Entries are upgraded to the current schema version before being written to the snapshot
+			if value, err = txn.upgrade(key, value); err != nil {
+				return
+			}
+
+			return aw.Write(key, value)
+		}); err != nil {
+			return
+		}
+
+		if err = txn.copyManualLookups(ss); err != nil {
+			return
+		}
+
+		if !m.opts.SoftDelete {
+			return
+		}
+
+		return txn.copyTombstones(ss)
 	}
 
 	return m.p.Snapshot(writeFn)
@@ -455,21 +779,56 @@ func (m *Mojura[T]) reindex(txn *Transaction[T]) (err error) {
 		return
 	}
 
+	if err = txn.txn.DeleteBucket(countsBktKey); err != nil {
+		return
+	}
+
+	if _, err = txn.txn.GetOrCreateBucket(countsBktKey); err != nil {
+		return
+	}
+
+	if err = txn.txn.DeleteBucket(lookupsBktKey); err != nil {
+		return
+	}
+
+	if _, err = txn.txn.GetOrCreateBucket(lookupsBktKey); err != nil {
+		return
+	}
+
 	fn := func(entryID string, t T) (err error) {
-		return txn.setRelationships(t.GetRelationships(), []byte(entryID))
+		if err = txn.setRelationships(m.getRelationships(t), []byte(entryID)); err != nil {
+			return
+		}
+
+		if err = txn.setLookups(getLookups(t), []byte(entryID)); err != nil {
+			err = fmt.Errorf("error setting lookups for <%s>: %v", entryID, err)
+			return
+		}
+
+		return
+	}
+
+	if err = txn.ForEach(fn, nil); err != nil {
+		return
 	}
 
-	return txn.ForEach(fn, nil)
+	// Manual lookups are not derived from the entries, so they're restored from their records
+	return txn.setAllManualLookups()
 }
 
 // New will insert a new entry with the given value and the associated relationships
 func (m *Mojura[T]) New(val T) (created T, err error) {
-	if m.opts.IsMirror {
+	return m.NewCtx(context.Background(), val)
+}
+
+// NewCtx will insert a new entry with the given value and the associated relationships
+func (m *Mojura[T]) NewCtx(ctx context.Context, val T) (created T, err error) {
+	if m.mirror.Load() {
 		err = ErrMirrorCannotPerformWriteActions
 		return
 	}
 
-	err = m.Transaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
 		created, err = txn.new(val)
 		return
 	})
@@ -479,7 +838,12 @@ func (m *Mojura[T]) New(val T) (created T, err error) {
 
 // Exists will notiy if an entry exists for a given entry ID
 func (m *Mojura[T]) Exists(entryID string) (exists bool, err error) {
-	err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.ExistsCtx(context.Background(), entryID)
+}
+
+// ExistsCtx will notiy if an entry exists for a given entry ID
+func (m *Mojura[T]) ExistsCtx(ctx context.Context, entryID string) (exists bool, err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		exists, err = txn.exists([]byte(entryID))
 		return
 	})
@@ -489,7 +853,12 @@ func (m *Mojura[T]) Exists(entryID string) (exists bool, err error) {
 
 // Get will attempt to get an entry by ID
 func (m *Mojura[T]) Get(entryID string) (val T, err error) {
-	err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.GetCtx(context.Background(), entryID)
+}
+
+// GetCtx will attempt to get an entry by ID
+func (m *Mojura[T]) GetCtx(ctx context.Context, entryID string) (val T, err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		val, err = txn.get([]byte(entryID))
 		return
 	})
@@ -497,9 +866,31 @@ func (m *Mojura[T]) Get(entryID string) (val T, err error) {
 	return
 }
 
+// GetByLookup will attempt to get an entry by lookup
+// Note: Will return ErrLookupNotFound if the lookup does not exist
+func (m *Mojura[T]) GetByLookup(lookupKey, lookupID string) (val T, err error) {
+	return m.GetByLookupCtx(context.Background(), lookupKey, lookupID)
+}
+
+// GetByLookupCtx will attempt to get an entry by lookup
+// Note: Will return ErrLookupNotFound if the lookup does not exist
+func (m *Mojura[T]) GetByLookupCtx(ctx context.Context, lookupKey, lookupID string) (val T, err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
+		val, err = txn.GetByLookup(lookupKey, lookupID)
+		return
+	})
+
+	return
+}
+
 // GetFiltered will attempt to get the filtered entries
 func (m *Mojura[T]) GetFiltered(o *FilteringOpts) (filtered []T, lastID string, err error) {
-	err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.GetFilteredCtx(context.Background(), o)
+}
+
+// GetFilteredCtx will attempt to get the filtered entries
+func (m *Mojura[T]) GetFilteredCtx(ctx context.Context, o *FilteringOpts) (filtered []T, lastID string, err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		filtered, lastID, err = txn.GetFiltered(o)
 		return
 	})
@@ -509,7 +900,12 @@ func (m *Mojura[T]) GetFiltered(o *FilteringOpts) (filtered []T, lastID string,
 
 // GetFilteredIDs will attempt to get the filtered entry IDs
 func (m *Mojura[T]) GetFilteredIDs(o *FilteringOpts) (filtered []string, lastID string, err error) {
-	err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.GetFilteredIDsCtx(context.Background(), o)
+}
+
+// GetFilteredIDsCtx will attempt to get the filtered entry IDs
+func (m *Mojura[T]) GetFilteredIDsCtx(ctx context.Context, o *FilteringOpts) (filtered []string, lastID string, err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		filtered, lastID, err = txn.GetFilteredIDs(o)
 		return
 	})
@@ -519,7 +915,12 @@ func (m *Mojura[T]) GetFilteredIDs(o *FilteringOpts) (filtered []string, lastID
 
 // AppendFiltered will attempt to append all entries associated with a set of given filters
 func (m *Mojura[T]) AppendFiltered(in []T, o *FilteringOpts) (filtered []T, lastID string, err error) {
-	err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.AppendFilteredCtx(context.Background(), in, o)
+}
+
+// AppendFilteredCtx will attempt to append all entries associated with a set of given filters
+func (m *Mojura[T]) AppendFilteredCtx(ctx context.Context, in []T, o *FilteringOpts) (filtered []T, lastID string, err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		filtered, lastID, err = txn.appendFiltered(in, o)
 		return
 	})
@@ -529,7 +930,12 @@ func (m *Mojura[T]) AppendFiltered(in []T, o *FilteringOpts) (filtered []T, last
 
 // AppendFilteredIDs will attempt to append all entry IDs associated with a set of given filters
 func (m *Mojura[T]) AppendFilteredIDs(in []string, o *FilteringOpts) (filtered []string, lastID string, err error) {
-	err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.AppendFilteredIDsCtx(context.Background(), in, o)
+}
+
+// AppendFilteredIDsCtx will attempt to append all entry IDs associated with a set of given filters
+func (m *Mojura[T]) AppendFilteredIDsCtx(ctx context.Context, in []string, o *FilteringOpts) (filtered []string, lastID string, err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		filtered, lastID, err = txn.appendFilteredIDs(in, o)
 		return
 	})
@@ -540,7 +946,13 @@ func (m *Mojura[T]) AppendFilteredIDs(in []string, o *FilteringOpts) (filtered [
 // GetFirst will attempt to get the first entry which matches the provided filters
 // Note: Will return ErrEntryNotFound if no match is found
 func (m *Mojura[T]) GetFirst(o *FilteringOpts) (val T, err error) {
-	if err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.GetFirstCtx(context.Background(), o)
+}
+
+// GetFirstCtx will attempt to get the first entry which matches the provided filters
+// Note: Will return ErrEntryNotFound if no match is found
+func (m *Mojura[T]) GetFirstCtx(ctx context.Context, o *FilteringOpts) (val T, err error) {
+	if err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		val, err = txn.getFirst(o)
 		return
 	}); err != nil {
@@ -553,7 +965,13 @@ func (m *Mojura[T]) GetFirst(o *FilteringOpts) (val T, err error) {
 // GetLast will attempt to get the last entry which matches the provided filters
 // Note: Will return ErrEntryNotFound if no match is found
 func (m *Mojura[T]) GetLast(o *FilteringOpts) (val T, err error) {
-	if err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.GetLastCtx(context.Background(), o)
+}
+
+// GetLastCtx will attempt to get the last entry which matches the provided filters
+// Note: Will return ErrEntryNotFound if no match is found
+func (m *Mojura[T]) GetLastCtx(ctx context.Context, o *FilteringOpts) (val T, err error) {
+	if err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		val, err = txn.getLast(o)
 		return
 	}); err != nil {
@@ -563,9 +981,46 @@ func (m *Mojura[T]) GetLast(o *FilteringOpts) (val T, err error) {
 	return
 }
 
+// Explain will return the plan which would be used for the provided filtering options
+func (m *Mojura[T]) Explain(o *FilteringOpts) (p Plan, err error) {
+	return m.ExplainCtx(context.Background(), o)
+}
+
+// ExplainCtx will return the plan which would be used for the provided filtering options
+func (m *Mojura[T]) ExplainCtx(ctx context.Context, o *FilteringOpts) (p Plan, err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
+		p, err = txn.explain(o)
+		return
+	})
+
+	return
+}
+
+// Count will return the number of entries which match the provided filtering options
+// Note: Limit is ignored
+func (m *Mojura[T]) Count(o *FilteringOpts) (n int64, err error) {
+	return m.CountCtx(context.Background(), o)
+}
+
+// CountCtx will return the number of entries which match the provided filtering options
+// Note: Limit is ignored
+func (m *Mojura[T]) CountCtx(ctx context.Context, o *FilteringOpts) (n int64, err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
+		n, err = txn.count(o)
+		return
+	})
+
+	return
+}
+
 // ForEach will iterate through each of the entries
 func (m *Mojura[T]) ForEach(fn ForEachFn[T], o *FilteringOpts) (err error) {
-	err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.ForEachCtx(context.Background(), fn, o)
+}
+
+// ForEachCtx will iterate through each of the entries
+func (m *Mojura[T]) ForEachCtx(ctx context.Context, fn ForEachFn[T], o *FilteringOpts) (err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		return txn.ForEach(fn, o)
 	})
 
@@ -574,16 +1029,48 @@ func (m *Mojura[T]) ForEach(fn ForEachFn[T], o *FilteringOpts) (err error) {
 
 // ForEachID will iterate through each of the entry IDs
 func (m *Mojura[T]) ForEachID(fn ForEachIDFn, o *FilteringOpts) (err error) {
-	err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.ForEachIDCtx(context.Background(), fn, o)
+}
+
+// ForEachIDCtx will iterate through each of the entry IDs
+func (m *Mojura[T]) ForEachIDCtx(ctx context.Context, fn ForEachIDFn, o *FilteringOpts) (err error) {
+	err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		return txn.ForEachID(fn, o)
 	})
 
 	return
 }
 
+// All will return an iterator of the entry IDs and entries which match the filtering options.
+// Entries are read in pages, and the read transaction of a page is released before the entries
+// of the page are yielded, so the loop body is free to write
+// Note: Iteration errors are set to errp (when not nil) once the iteration has ended
+// Note: Entries written during the iteration are only included when they follow the current page
+func (m *Mojura[T]) All(ctx context.Context, o *FilteringOpts, errp *error) iter.Seq2[string, T] {
+	return makeSeq2(errp, func(yield func(string, T) bool) error {
+		return m.iteratePages(ctx, o, yield)
+	})
+}
+
+// IDs will return an iterator of the entry IDs which match the filtering options. Entry IDs
+// are read in pages, and the read transaction of a page is released before the entry IDs of
+// the page are yielded, so the loop body is free to write
+// Note: Iteration errors are set to errp (when not nil) once the iteration has ended
+// Note: Entries written during the iteration are only included when they follow the current page
+func (m *Mojura[T]) IDs(ctx context.Context, o *FilteringOpts, errp *error) iter.Seq[string] {
+	return makeSeq(errp, func(yield func(string) bool) error {
+		return m.iterateIDPages(ctx, o, yield)
+	})
+}
+
 // Cursor will return an iterating cursor
 func (m *Mojura[T]) Cursor(fn func(Cursor[T]) error, fs ...Filter) (err error) {
-	if err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	return m.CursorCtx(context.Background(), fn, fs...)
+}
+
+// CursorCtx will return an iterating cursor
+func (m *Mojura[T]) CursorCtx(ctx context.Context, fn func(Cursor[T]) error, fs ...Filter) (err error) {
+	if err = m.ReadTransaction(ctx, func(txn *Transaction[T]) (err error) {
 		var c Cursor[T]
 		if c, err = txn.cursor(fs); err != nil {
 			return
@@ -601,12 +1088,19 @@ func (m *Mojura[T]) Cursor(fn func(Cursor[T]) error, fs ...Filter) (err error) {
 // Note: This will not check to see if the entry exists beforehand. If this functionality
 // is needed, look into using the Edit method
 func (m *Mojura[T]) Put(entryID string, val T) (updated T, err error) {
-	if m.opts.IsMirror {
+	return m.PutCtx(context.Background(), entryID, val)
+}
+
+// PutCtx will place an entry at a given entry ID
+// Note: This will not check to see if the entry exists beforehand. If this functionality
+// is needed, look into using the Edit method
+func (m *Mojura[T]) PutCtx(ctx context.Context, entryID string, val T) (updated T, err error) {
+	if m.mirror.Load() {
 		err = ErrMirrorCannotPerformWriteActions
 		return
 	}
 
-	err = m.Transaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
 		updated, err = txn.put([]byte(entryID), val)
 		return
 	})
@@ -616,12 +1110,17 @@ func (m *Mojura[T]) Put(entryID string, val T) (updated T, err error) {
 
 // Update will attempt to edit an entry by ID
 func (m *Mojura[T]) Update(entryID string, fn UpdateFn[T]) (updated T, err error) {
-	if m.opts.IsMirror {
+	return m.UpdateCtx(context.Background(), entryID, fn)
+}
+
+// UpdateCtx will attempt to edit an entry by ID
+func (m *Mojura[T]) UpdateCtx(ctx context.Context, entryID string, fn UpdateFn[T]) (updated T, err error) {
+	if m.mirror.Load() {
 		err = ErrMirrorCannotPerformWriteActions
 		return
 	}
 
-	err = m.Transaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
 		updated, err = txn.update([]byte(entryID), fn)
 		return
 	})
@@ -630,13 +1129,19 @@ func (m *Mojura[T]) Update(entryID string, fn UpdateFn[T]) (updated T, err error
 }
 
 // Delete will remove an entry and it's related relationship IDs
+// Note: When Opts.SoftDelete is set, the entry is moved to the tombstones
 func (m *Mojura[T]) Delete(entryID string) (deleted T, err error) {
-	if m.opts.IsMirror {
+	return m.DeleteCtx(context.Background(), entryID)
+}
+
+// DeleteCtx will remove an entry and it's related relationship IDs
+func (m *Mojura[T]) DeleteCtx(ctx context.Context, entryID string) (deleted T, err error) {
+	if m.mirror.Load() {
 		err = ErrMirrorCannotPerformWriteActions
 		return
 	}
 
-	err = m.Transaction(context.Background(), func(txn *Transaction[T]) (err error) {
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
 		deleted, err = txn.delete([]byte(entryID))
 		return
 	})
@@ -644,18 +1149,544 @@ func (m *Mojura[T]) Delete(entryID string) (deleted T, err error) {
 	return
 }
 
+// Restore will restore a soft-deleted entry, along with it's relationships and lookups
+// Note: The version of the entry is incremented from the version it was deleted with
+func (m *Mojura[T]) Restore(entryID string) (restored T, err error) {
+	return m.RestoreCtx(context.Background(), entryID)
+}
+
+// RestoreCtx will restore a soft-deleted entry, along with it's relationships and lookups
+func (m *Mojura[T]) RestoreCtx(ctx context.Context, entryID string) (restored T, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		restored, err = txn.restore([]byte(entryID))
+		return
+	})
+
+	return
+}
+
+// Purge will permanently remove the soft-deleted entries which were deleted before olderThan,
+// the number of purged entries is returned
+func (m *Mojura[T]) Purge(olderThan time.Time) (n int64, err error) {
+	return m.PurgeCtx(context.Background(), olderThan)
+}
+
+// PurgeCtx will permanently remove the soft-deleted entries which were deleted before olderThan,
+// the number of purged entries is returned
+func (m *Mojura[T]) PurgeCtx(ctx context.Context, olderThan time.Time) (n int64, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		n, err = txn.purge(olderThan)
+		return
+	})
+
+	return
+}
+
+// Migrate will upgrade all of the entries which are behind the current schema version (as
+// set by Opts.Upgraders) and append the upgraded entries to the history. Once complete, the
+// relationships, lookups and counts are rebuilt from the upgraded entries. The number of
+// upgraded entries is returned
+func (m *Mojura[T]) Migrate(ctx context.Context) (n int64, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		if n, err = txn.migrate(); err != nil {
+			return
+		}
+
+		return m.reindex(txn)
+	})
+
+	return
+}
+
+// Rekey will widen the index length of sequential entry IDs. All sequential entries are
+// moved to entry IDs of the new index length and the moves are appended to the history. The
+// number of moved entries is returned
+// Note: Mirrors must be running a version which supports move actions prior to a Rekey
+// Note: Rekey is only supported when using the SequentialIDGenerator
+func (m *Mojura[T]) Rekey(ctx context.Context, indexLength int) (n int64, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	// The current generator is read within the transaction, so it's serialized with other rekeys
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		current, ok := txn.getIDGenerator().(*SequentialIDGenerator)
+		if !ok {
+			return ErrRekeyNotSupported
+		}
+
+		if indexLength <= current.IndexLength() {
+			return ErrInvalidIndexLength
+		}
+
+		n, err = txn.rekey(current, NewSequentialIDGenerator(indexLength))
+		return
+	})
+
+	return
+}
+
+// NewMany will insert new entries with the given values and return the created entries
+// in the same order as the provided values. All entries are written within a single
+// transaction, using a contiguous range of entry IDs
+func (m *Mojura[T]) NewMany(ctx context.Context, vals []T) (created []T, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		created, err = txn.newMany(vals)
+		return
+	})
+
+	return
+}
+
+// PutMany will place entries at the given entry IDs. All entries are written within a
+// single transaction
+// Note: This will not check to see if the entries exist beforehand
+func (m *Mojura[T]) PutMany(ctx context.Context, vals map[string]T) (updated map[string]T, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		updated, err = txn.putMany(vals)
+		return
+	})
+
+	return
+}
+
+// DeleteFiltered will remove all of the entries which match the filtering options and
+// return the number of entries deleted. All deletions occur within a single transaction
+// Note: Limit is ignored
+func (m *Mojura[T]) DeleteFiltered(ctx context.Context, o *FilteringOpts) (n int64, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		n, err = txn.deleteFiltered(o)
+		return
+	})
+
+	return
+}
+
+// UpdateFiltered will update all of the entries which match the filtering options and
+// return the number of entries updated. All updates occur within a single transaction.
+// Returning Break from the update func will end the updates early, leaving the current
+// entry unchanged
+// Note: Limit is ignored
+func (m *Mojura[T]) UpdateFiltered(ctx context.Context, o *FilteringOpts, fn UpdateFn[T]) (n int64, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		n, err = txn.updateFiltered(o, fn)
+		return
+	})
+
+	return
+}
+
+// Create will place an entry at a given entry ID if an entry does not already exist
+// Note: Will return ErrEntryExists if an entry exists for the given entry ID
+func (m *Mojura[T]) Create(entryID string, val T) (created T, err error) {
+	return m.CreateCtx(context.Background(), entryID, val)
+}
+
+// CreateCtx will place an entry at a given entry ID if an entry does not already exist
+// Note: Will return ErrEntryExists if an entry exists for the given entry ID
+func (m *Mojura[T]) CreateCtx(ctx context.Context, entryID string, val T) (created T, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		created, err = txn.create([]byte(entryID), val)
+		return
+	})
+
+	return
+}
+
+// Upsert will update an existing entry or insert a new entry at a given entry ID
+func (m *Mojura[T]) Upsert(entryID string, fn UpsertFn[T]) (updated T, err error) {
+	return m.UpsertCtx(context.Background(), entryID, fn)
+}
+
+// UpsertCtx will update an existing entry or insert a new entry at a given entry ID
+func (m *Mojura[T]) UpsertCtx(ctx context.Context, entryID string, fn UpsertFn[T]) (updated T, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		updated, err = txn.upsert([]byte(entryID), fn)
+		return
+	})
+
+	return
+}
+
+// PutIfVersion will place an entry at a given entry ID if the current version matches the
+// expected version. An expected version of 0 will only match entries which do not exist
+// Note: Entries written before versions were introduced have a version of 0 and must be
+// written once (e.g. with Put) before their version can be matched
+// Note: Will return ErrVersionConflict if the versions do not match
+func (m *Mojura[T]) PutIfVersion(entryID string, expectedVersion int64, val T) (updated T, err error) {
+	return m.PutIfVersionCtx(context.Background(), entryID, expectedVersion, val)
+}
+
+// PutIfVersionCtx will place an entry at a given entry ID if the current version matches the
+// expected version. An expected version of 0 will only match entries which do not exist
+// Note: Entries written before versions were introduced have a version of 0 and must be
+// written once (e.g. with Put) before their version can be matched
+// Note: Will return ErrVersionConflict if the versions do not match
+func (m *Mojura[T]) PutIfVersionCtx(ctx context.Context, entryID string, expectedVersion int64, val T) (updated T, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		updated, err = txn.putIfVersion([]byte(entryID), expectedVersion, val)
+		return
+	})
+
+	return
+}
+
+// DeleteIfVersion will remove an entry if the current version matches the expected version
+// Note: Will return ErrVersionConflict if the versions do not match
+func (m *Mojura[T]) DeleteIfVersion(entryID string, expectedVersion int64) (deleted T, err error) {
+	return m.DeleteIfVersionCtx(context.Background(), entryID, expectedVersion)
+}
+
+// DeleteIfVersionCtx will remove an entry if the current version matches the expected version
+// Note: Will return ErrVersionConflict if the versions do not match
+func (m *Mojura[T]) DeleteIfVersionCtx(ctx context.Context, entryID string, expectedVersion int64) (deleted T, err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		deleted, err = txn.deleteIfVersion([]byte(entryID), expectedVersion)
+		return
+	})
+
+	return
+}
+
+// SetLookup will set a unique lookup for an entry
+// Note: Will return ErrLookupExists if the lookup is set for another entry. The lookup is
+// removed when the entry is deleted
+func (m *Mojura[T]) SetLookup(lookupKey, lookupID, entryID string) (err error) {
+	return m.SetLookupCtx(context.Background(), lookupKey, lookupID, entryID)
+}
+
+// SetLookupCtx will set a unique lookup for an entry
+// Note: Will return ErrLookupExists if the lookup is set for another entry. The lookup is
+// removed when the entry is deleted
+func (m *Mojura[T]) SetLookupCtx(ctx context.Context, lookupKey, lookupID, entryID string) (err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		return txn.SetLookup(lookupKey, lookupID, entryID)
+	})
+
+	return
+}
+
+// RemoveLookup will remove a unique lookup
+func (m *Mojura[T]) RemoveLookup(lookupKey, lookupID string) (err error) {
+	return m.RemoveLookupCtx(context.Background(), lookupKey, lookupID)
+}
+
+// RemoveLookupCtx will remove a unique lookup
+func (m *Mojura[T]) RemoveLookupCtx(ctx context.Context, lookupKey, lookupID string) (err error) {
+	if m.mirror.Load() {
+		err = ErrMirrorCannotPerformWriteActions
+		return
+	}
+
+	err = m.Transaction(ctx, func(txn *Transaction[T]) (err error) {
+		return txn.RemoveLookup(lookupKey, lookupID)
+	})
+
+	return
+}
+
+// Subscribe will return a channel of the changes committed after subscribing, in commit order.
+// Only changes to entries which match the filters are sent, writes are matched by either the
+// written or the previous value and deletes by the deleted value. The channel is closed once the
+// context is done
+// Note: Commits do not wait for subscribers. A subscription whose buffer is full is ended by
+// closing the channel, use SubscribeFrom with the position of the last received change to resume
+func (m *Mojura[T]) Subscribe(ctx context.Context, o *FilteringOpts) (changes <-chan Change[T], err error) {
+	var match changeMatchFn[T]
+	if match, err = m.newChangeMatchFn(o); err != nil {
+		return
+	}
+
+	return m.feed.subscribe(ctx, match, 0, false)
+}
+
+// SubscribeFrom will subscribe to changes committed after the provided position. Positions
+// are resumed from the in-memory change backlog, falling back to the history exported to the
+// Source. ErrPositionUnavailable is returned when the changes following the position are no
+// longer available
+func (m *Mojura[T]) SubscribeFrom(ctx context.Context, o *FilteringOpts, position uint64) (changes <-chan Change[T], err error) {
+	var match changeMatchFn[T]
+	if match, err = m.newChangeMatchFn(o); err != nil {
+		return
+	}
+
+	return m.feed.subscribe(ctx, match, position, true)
+}
+
+// ReplicationStatus will return the import state of the instance
+func (m *Mojura[T]) ReplicationStatus() (status ReplicationStatus) {
+	return m.rs.get()
+}
+
+// Position will return the position of the last change committed by the instance
+// Note: Mirrors do not commit changes, the imported position is included in the ReplicationStatus
+func (m *Mojura[T]) Position() (position uint64) {
+	return m.feed.getPosition()
+}
+
+// WaitUntilCaughtUp will wait until the change at the position has been imported.
+// For read-after-write flows, pass the Position of the primary taken after the write
+func (m *Mojura[T]) WaitUntilCaughtUp(ctx context.Context, position uint64) (err error) {
+	return m.rs.wait(ctx, position)
+}
+
+// RestoreTo will build a database within dir by replaying the history exported to the Source,
+// up to and including the blocks written at the timestamp
+// Note: Blocks written without a timestamp are only excluded when their chunk was created
+// after the timestamp
+// Note: The restored database can be opened as a mirror or promoted to a primary
+func (m *Mojura[T]) RestoreTo(ctx context.Context, dir string, timestamp time.Time) (err error) {
+	var restored *Mojura[T]
+	if restored, err = m.restore(ctx, dir, timestamp, -1); err != nil {
+		return
+	}
+
+	return restored.Close()
+}
+
+// RestoreToBlock will build a database within dir by replaying the first number of blocks
+// within the history exported to the Source
+func (m *Mojura[T]) RestoreToBlock(ctx context.Context, dir string, blocks int64) (err error) {
+	var restored *Mojura[T]
+	if restored, err = m.restore(ctx, dir, time.Time{}, blocks); err != nil {
+		return
+	}
+
+	return restored.Close()
+}
+
+// At will return a read-only view of the database as it was at the timestamp. The view is
+// restored from the history into a temporary directory, which is removed when the view is closed
+func (m *Mojura[T]) At(timestamp time.Time) (view *Mojura[T], err error) {
+	return m.AtCtx(context.Background(), timestamp)
+}
+
+// AtCtx will return a read-only view of the database as it was at the timestamp
+// Note: Blocks written without a timestamp are only excluded when their chunk was created
+// after the timestamp
+func (m *Mojura[T]) AtCtx(ctx context.Context, timestamp time.Time) (view *Mojura[T], err error) {
+	var dir string
+	if dir, err = os.MkdirTemp("", "mojura-view-"); err != nil {
+		return
+	}
+
+	if view, err = m.restore(ctx, dir, timestamp, -1); err != nil {
+		os.RemoveAll(dir)
+		return
+	}
+
+	view.viewDir = dir
+	return
+}
+
+// History will return the revisions of an entry, oldest first. The values of the revisions are
+// read from the history, ErrRevisionUnavailable is returned when a revision is no longer available
+// Note: Revisions are only indexed while Opts.IndexHistory is set
+func (m *Mojura[T]) History(ctx context.Context, entryID string) (revisions []Revision[T], err error) {
+	return m.history(ctx, entryID)
+}
+
+// Promote will promote a mirror to a primary. The consumer is stopped, the remaining history
+// is imported from the Source, the current index is restored and a producer is started
+func (m *Mojura[T]) Promote(ctx context.Context) (err error) {
+	m.roleMux.Lock()
+	defer m.roleMux.Unlock()
+
+	if err = m.closeConsumer(); err != nil {
+		return
+	}
+
+	if err = m.importTransaction(ctx, func(txn *Transaction[T]) (err error) {
+		return txn.restoreIndex()
+	}); err != nil {
+		err = fmt.Errorf("error restoring index: %v", err)
+		return m.restartMirror(err)
+	}
+
+	// Primary initialization will catch up to the Source before starting the producer
+	if err = m.primaryInitialization(); err != nil {
+		err = fmt.Errorf("error initializing primary: %v", err)
+		return m.abortPromotion(err)
+	}
+
+	// Changes continue from the last position imported from the primary
+	if err = m.initPosition(); err != nil {
+		err = fmt.Errorf("error initializing position: %v", err)
+		return m.abortPromotion(err)
+	}
+
+	m.mirror.Store(false)
+	return
+}
+
+// Demote will demote a primary to a mirror. Writes are rejected once called, the producer is
+// closed after in-flight transactions have completed and a consumer is started
+func (m *Mojura[T]) Demote(ctx context.Context) (err error) {
+	m.roleMux.Lock()
+	defer m.roleMux.Unlock()
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	m.mux.Lock()
+	if err = m.closeProducer(); err != nil {
+		m.mux.Unlock()
+		return
+	}
+	m.mux.Unlock()
+
+	if err = m.mirrorInitialization(); err != nil {
+		err = fmt.Errorf("error initializing mirror: %v", err)
+		return
+	}
+
+	return
+}
+
+func (m *Mojura[T]) closeConsumer() (err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	switch {
+	case m.closed:
+		return errors.ErrIsClosed
+	case !m.mirror.Load():
+		return ErrAlreadyPrimary
+	case m.c == nil:
+		return
+	}
+
+	if err = m.c.Close(); err != nil {
+		err = fmt.Errorf("error closing consumer: %v", err)
+		return
+	}
+
+	m.c = nil
+	return
+}
+
+func (m *Mojura[T]) closeProducer() (err error) {
+	switch {
+	case m.closed:
+		return errors.ErrIsClosed
+	case m.mirror.Load():
+		return ErrAlreadyMirror
+	}
+
+	// Writes are rejected while the producer is closed, as the mutex is held by the caller
+	if err = m.p.Close(); err != nil {
+		err = fmt.Errorf("error closing producer: %v", err)
+		return
+	}
+
+	m.p = nil
+	m.c = nil
+	m.mirror.Store(true)
+	return
+}
+
+// abortPromotion will close the producer started by a failed promotion, if one was started,
+// and restart the consumer
+func (m *Mojura[T]) abortPromotion(inbound error) (err error) {
+	m.mux.Lock()
+	if m.p != nil {
+		if err = m.p.Close(); err != nil {
+			inbound = fmt.Errorf("%v, error closing producer: %v", inbound, err)
+		}
+
+		m.p = nil
+		m.c = nil
+	}
+	m.mux.Unlock()
+
+	return m.restartMirror(inbound)
+}
+
+// restartMirror will restart the consumer after a failed promotion
+func (m *Mojura[T]) restartMirror(inbound error) (err error) {
+	if err = m.mirrorInitialization(); err != nil {
+		return fmt.Errorf("%v, error restarting mirror: %v", inbound, err)
+	}
+
+	return inbound
+}
+
 // Transaction will initialize a transaction
 func (m *Mojura[T]) Transaction(ctx context.Context, fn func(*Transaction[T]) error) (err error) {
 	m.mux.RLock()
 	defer m.mux.RUnlock()
 
-	if m.opts.IsMirror {
+	if m.mirror.Load() {
 		err = ErrMirrorCannotPerformWriteActions
 		return
 	}
 
-	err = m.transaction(func(txn backend.Transaction, ktxn *kiroku.Transaction) (Transaction[T], error) {
-		return m.runTransaction(ctx, txn, ktxn, fn)
+	err = m.transaction(func(txn backend.Transaction, ktxn *kiroku.Transaction, startedAt int64) (Transaction[T], error) {
+		return m.runTransaction(ctx, txn, ktxn, func(txn *Transaction[T]) error {
+			txn.chunkCreatedAt = startedAt
+			return fn(txn)
+		})
 	})
 
 	return
@@ -677,7 +1708,7 @@ func (m *Mojura[T]) ReadTransaction(ctx context.Context, fn func(*Transaction[T]
 
 // Batch will initialize a batch
 func (m *Mojura[T]) Batch(ctx context.Context, fn func(*Transaction[T]) error) (err error) {
-	if m.opts.IsMirror {
+	if m.mirror.Load() {
 		err = ErrMirrorCannotPerformWriteActions
 		return
 	}
@@ -687,7 +1718,7 @@ func (m *Mojura[T]) Batch(ctx context.Context, fn func(*Transaction[T]) error) (
 
 // Snapshot will create a snapshot of the database in it's current state
 func (m *Mojura[T]) Snapshot(ctx context.Context) (err error) {
-	if m.opts.IsMirror {
+	if m.mirror.Load() {
 		err = ErrMirrorCannotPerformWriteActions
 		return
 	}
@@ -702,7 +1733,7 @@ func (m *Mojura[T]) Snapshot(ctx context.Context) (err error) {
 
 // Reindex will reindex the relationships
 func (m *Mojura[T]) Reindex(ctx context.Context) (err error) {
-	if m.opts.IsMirror {
+	if m.mirror.Load() {
 		err = ErrMirrorCannotPerformWriteActions
 		return
 	}
@@ -724,6 +1755,7 @@ func (m *Mojura[T]) Close() (err error) {
 	}
 
 	m.closed = true
+	m.feed.close()
 
 	var errs errors.ErrorList
 	errs.Push(m.db.Close())
@@ -731,6 +1763,10 @@ func (m *Mojura[T]) Close() (err error) {
 		errs.Push(m.c.Close())
 	}
 
+	if len(m.viewDir) > 0 {
+		errs.Push(os.RemoveAll(m.viewDir))
+	}
+
 	return errs.Err()
 }
 
@@ -1,16 +1,26 @@
 package mojura
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gdbu/stringset"
 	"github.com/hatchify/errors"
+	"github.com/mojura/backend"
+	"github.com/mojura/enkodo"
 	"github.com/mojura/kiroku"
+	"github.com/mojura/mojura/action"
 	"github.com/mojura/mojura/filters"
 )
 
@@ -831,6 +841,191 @@ func TestMojura_GetFilteredIDs_seek(t *testing.T) {
 	}
 }
 
+func TestMojura_GetFilteredIDs_or(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	entries := []*testStruct{
+		newTestStruct("user_1", "contact_1", "group_1", "0"),
+		newTestStruct("user_2", "contact_1", "group_2", "1"),
+		newTestStruct("user_3", "contact_1", "group_1", "2"),
+		newTestStruct("user_2", "contact_2", "group_1", "3"),
+		newTestStruct("user_1", "contact_2", "group_2", "4"),
+		newTestStruct("user_3", "contact_2", "group_2", "5"),
+	}
+
+	for i, entry := range entries {
+		if entries[i], err = c.New(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type testcase struct {
+		filter   Filter
+		reverse  bool
+		expected []string
+	}
+
+	tcs := []testcase{
+		{
+			filter:   filters.Or(filters.Match("users", "user_1"), filters.Match("users", "user_2")),
+			expected: []string{"00000000", "00000001", "00000003", "00000004"},
+		},
+		{
+			filter:   filters.Or(filters.Match("users", "user_1"), filters.Match("users", "user_2")),
+			reverse:  true,
+			expected: []string{"00000004", "00000003", "00000001", "00000000"},
+		},
+		{
+			// Overlapping filters should only return each entry once
+			filter:   filters.Or(filters.Match("users", "user_1"), filters.Match("groups", "group_2")),
+			expected: []string{"00000000", "00000001", "00000004", "00000005"},
+		},
+		{
+			filter: filters.Or(
+				filters.Match("users", "user_1"),
+				filters.And(filters.Match("users", "user_2"), filters.Match("groups", "group_1")),
+			),
+			expected: []string{"00000000", "00000003", "00000004"},
+		},
+		{
+			// Comparison cursors iterate by relationship ID and must be merged in entry ID order
+			filter:   filters.Or(filters.Match("users", "user_3"), filters.GreaterThan("contacts", "contact_1")),
+			expected: []string{"00000002", "00000003", "00000004", "00000005"},
+		},
+		{
+			filter:   filters.Or(filters.Match("users", "user_4"), filters.Match("users", "user_5")),
+			expected: []string{},
+		},
+	}
+
+	for i, tc := range tcs {
+		o := NewFilteringOpts(tc.filter)
+		o.Reverse = tc.reverse
+		o.Limit = 1
+
+		// Iterate one entry at a time to ensure LastID pagination works across the union
+		filtered := []string{}
+		for {
+			var ids []string
+			if ids, o.LastID, err = c.GetFilteredIDs(o); err != nil {
+				t.Fatalf("error getting filtered IDs (test case #%d): %v", i, err)
+			}
+
+			if len(ids) == 0 {
+				break
+			}
+
+			filtered = append(filtered, ids...)
+		}
+
+		if fmt.Sprint(filtered) != fmt.Sprint(tc.expected) {
+			t.Fatalf("invalid IDs, expected %v and received %v (test case #%d)", tc.expected, filtered, i)
+		}
+	}
+}
+
+func TestMojura_Explain(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	for i := 0; i < 10; i++ {
+		groupID := "group_1"
+		if i == 7 {
+			groupID = "group_2"
+		}
+
+		if _, err = c.New(newTestStruct("user_1", "contact_1", groupID, "FOO FOO")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type testcase struct {
+		filters []Filter
+
+		expectedPrimary   int
+		expectedReordered bool
+		expectedEstimates []int64
+		expectedIDs       []string
+	}
+
+	tcs := []testcase{
+		{
+			filters:           []Filter{filters.Match("users", "user_1"), filters.Match("groups", "group_2")},
+			expectedPrimary:   1,
+			expectedReordered: true,
+			expectedIDs:       []string{"00000007"},
+		},
+		{
+			filters:           []Filter{filters.Match("groups", "group_2"), filters.Match("users", "user_1")},
+			expectedPrimary:   0,
+			expectedReordered: false,
+			expectedIDs:       []string{"00000007"},
+		},
+		{
+			// Comparison filters with a relationship key determine the result order and must remain primary
+			filters:           []Filter{filters.GreaterThan("contacts", "contact_0"), filters.Match("groups", "group_2")},
+			expectedPrimary:   0,
+			expectedReordered: false,
+			expectedIDs:       []string{"00000007"},
+		},
+		{
+			// Entry comparisons are estimated after the match counts, so the scan stops at the match count
+			filters:           []Filter{filters.GreaterThan("", "00000000"), filters.Match("groups", "group_2")},
+			expectedPrimary:   1,
+			expectedReordered: true,
+			expectedEstimates: []int64{1, 1},
+			expectedIDs:       []string{"00000007"},
+		},
+	}
+
+	for i, tc := range tcs {
+		o := NewFilteringOpts(tc.filters...)
+
+		var p Plan
+		if p, err = c.Explain(o); err != nil {
+			t.Fatal(err)
+		}
+
+		if p.Reordered != tc.expectedReordered {
+			t.Fatalf("invalid reordered value, expected %v and received %v (test case #%d)", tc.expectedReordered, p.Reordered, i)
+		}
+
+		if primary := p.Filters[0].Index; primary != tc.expectedPrimary {
+			t.Fatalf("invalid primary index, expected %d and received %d (test case #%d)", tc.expectedPrimary, primary, i)
+		}
+
+		for j, expected := range tc.expectedEstimates {
+			if estimate := p.Filters[j].Estimate; estimate != expected {
+				t.Fatalf("invalid estimate for filter #%d, expected %d and received %d (test case #%d)", j, expected, estimate, i)
+			}
+		}
+
+		var ids []string
+		if ids, _, err = c.GetFilteredIDs(o); err != nil {
+			t.Fatal(err)
+		}
+
+		if fmt.Sprint(ids) != fmt.Sprint(tc.expectedIDs) {
+			t.Fatalf("invalid IDs, expected %v and received %v (test case #%d)", tc.expectedIDs, ids, i)
+		}
+	}
+}
+
 func TestMojura_AppendFiltered(t *testing.T) {
 	var (
 		c   *Mojura[*testStruct]
@@ -905,25 +1100,2319 @@ func TestMojura_AppendFiltered(t *testing.T) {
 			expectedCount: 3,
 		},
 
-		{
-			tag:           "baz",
-			group:         "group_2",
-			expectedCount: 3,
-		},
+		{
+			tag:           "baz",
+			group:         "group_2",
+			expectedCount: 3,
+		},
+	}
+
+	for _, entry := range entries {
+		if _, err = c.New(&entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = runCases(createCases); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMojura_AppendFilteredIDs(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	entries := []testStruct{
+		makeTestStruct("user_1", "contact_1", "group_1", "FOO FOO", "foo", "bar", "baz"),
+		makeTestStruct("user_1", "contact_1", "group_2", "FOO FOO", "bar"),
+		makeTestStruct("user_1", "contact_1", "group_1", "FOO FOO", "baz"),
+	}
+
+	type testcase struct {
+		tag           string
+		group         string
+		expectedCount int
+	}
+
+	runCases := func(cases []testcase) (err error) {
+		for _, tc := range cases {
+			var ids []string
+			filter := filters.Match("tags", tc.tag)
+			o := NewFilteringOpts(filter)
+			if ids, _, err = c.AppendFilteredIDs(ids, o); err != nil {
+				return
+			}
+
+			filter = filters.Match("groups", tc.group)
+			o = NewFilteringOpts(filter)
+			if ids, _, err = c.AppendFilteredIDs(ids, o); err != nil {
+				return
+			}
+
+			if len(ids) != tc.expectedCount {
+				err = fmt.Errorf("invalid number of entries, expected %d and received %d for tag of <%s> and group of <%s>", tc.expectedCount, len(ids), tc.tag, tc.group)
+			}
+		}
+
+		return
+	}
+
+	createCases := []testcase{
+		{
+			tag:           "foo",
+			group:         "group_1",
+			expectedCount: 3,
+		},
+		{
+			tag:           "bar",
+			group:         "group_1",
+			expectedCount: 4,
+		},
+
+		{
+			tag:           "baz",
+			group:         "group_1",
+			expectedCount: 4,
+		},
+		{
+			tag:           "foo",
+			group:         "group_2",
+			expectedCount: 2,
+		},
+		{
+			tag:           "bar",
+			group:         "group_2",
+			expectedCount: 3,
+		},
+
+		{
+			tag:           "baz",
+			group:         "group_2",
+			expectedCount: 3,
+		},
+	}
+
+	for _, entry := range entries {
+		if _, err = c.New(&entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = runCases(createCases); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMojura_Count(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { testTeardown(c, t) }()
+
+	entries := []*testStruct{
+		newTestStruct("user_1", "contact_1", "group_1", "0"),
+		newTestStruct("user_1", "contact_2", "group_1", "1"),
+		newTestStruct("user_2", "contact_1", "group_2", "2"),
+		newTestStruct("user_1", "contact_1", "group_2", "3"),
+	}
+
+	for i, entry := range entries {
+		if entries[i], err = c.New(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = c.Delete(entries[3].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Update(entries[1].ID, func(ts *testStruct) (err error) {
+		ts.UserID = "user_2"
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		opts     *FilteringOpts
+		expected int64
+	}
+
+	tcs := []testcase{
+		{opts: nil, expected: 3},
+		{opts: NewFilteringOpts(filters.Match("users", "user_1")), expected: 1},
+		{opts: NewFilteringOpts(filters.Match("users", "user_2")), expected: 2},
+		{opts: NewFilteringOpts(filters.Match("users", "user_3")), expected: 0},
+		{opts: NewFilteringOpts(filters.Match("contacts", "contact_1"), filters.Match("groups", "group_2")), expected: 1},
+		{opts: &FilteringOpts{LastID: entries[0].ID}, expected: 2},
+	}
+
+	check := func(stage string) {
+		for i, tc := range tcs {
+			var n int64
+			if n, err = c.Count(tc.opts); err != nil {
+				t.Fatalf("error counting %s (test case #%d): %v", stage, i, err)
+			}
+
+			if n != tc.expected {
+				t.Fatalf("invalid count %s, expected %d and received %d (test case #%d)", stage, tc.expected, n, i)
+			}
+		}
+	}
+
+	check("before reopen")
+
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+
+	check("after reopen")
+
+	if err = c.Reindex(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	check("after reindex")
+}
+
+func TestMojura_Lookups(t *testing.T) {
+	var (
+		c   *Mojura[*testLookupStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	if c, err = New[*testLookupStruct](MakeOpts("test_lookups", testDir), "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.Close() }()
+
+	foo := newTestLookupStruct("user_1", "foo", "foo@example.com")
+	bar := newTestLookupStruct("user_2", "bar", "bar@example.com")
+
+	var created, other *testLookupStruct
+	if created, err = c.New(foo); err != nil {
+		t.Fatal(err)
+	}
+
+	if other, err = c.New(bar); err != nil {
+		t.Fatal(err)
+	}
+
+	checkLookup := func(lookupID, expected string) {
+		var val *testLookupStruct
+		val, err = c.GetByLookup("email", lookupID)
+		switch {
+		case len(expected) == 0 && err == ErrLookupNotFound:
+		case err != nil:
+			t.Fatalf("error getting <%s>: %v", lookupID, err)
+		case val.ID != expected:
+			t.Fatalf("invalid entry ID for <%s>, expected <%s> and received <%s>", lookupID, expected, val.ID)
+		}
+	}
+
+	checkLookup("foo@example.com", created.ID)
+	checkLookup("bar@example.com", other.ID)
+
+	dupe := newTestLookupStruct("user_3", "dupe", "foo@example.com")
+	if _, err = c.New(dupe); err != ErrLookupExists {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrLookupExists, err)
+	}
+
+	if _, err = c.Update(other.ID, func(ts *testLookupStruct) (err error) {
+		ts.Email = "foo@example.com"
+		return
+	}); err != ErrLookupExists {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrLookupExists, err)
+	}
+
+	if _, err = c.Update(created.ID, func(ts *testLookupStruct) (err error) {
+		ts.Email = "foo@example.org"
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkLookup("foo@example.com", "")
+	checkLookup("foo@example.org", created.ID)
+
+	if err = c.SetLookup("username", "foobar", created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.SetLookup("username", "foobar", other.ID); err != ErrLookupExists {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrLookupExists, err)
+	}
+
+	if err = c.SetLookup("username", "baz", "unknown"); err != ErrEntryNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryNotFound, err)
+	}
+
+	var val *testLookupStruct
+	if val, err = c.GetByLookup("username", "foobar"); err != nil {
+		t.Fatal(err)
+	} else if val.ID != created.ID {
+		t.Fatalf("invalid entry ID, expected <%s> and received <%s>", created.ID, val.ID)
+	}
+
+	if err = c.RemoveLookup("username", "foobar"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.GetByLookup("username", "foobar"); err != ErrLookupNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrLookupNotFound, err)
+	}
+
+	checkManualLookup := func(lookupID, expected string) {
+		var val *testLookupStruct
+		val, err = c.GetByLookup("username", lookupID)
+		switch {
+		case len(expected) == 0 && err == ErrLookupNotFound:
+		case err != nil:
+			t.Fatalf("error getting <%s>: %v", lookupID, err)
+		case val.ID != expected:
+			t.Fatalf("invalid entry ID for <%s>, expected <%s> and received <%s>", lookupID, expected, val.ID)
+		}
+	}
+
+	if err = c.SetLookup("username", "foo", created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.SetLookup("username", "bar", other.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Manual lookups are retained when the entry is updated
+	if _, err = c.Update(created.ID, func(ts *testLookupStruct) (err error) {
+		ts.Value = "updated"
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Delete(other.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	checkLookup("bar@example.com", "")
+	checkManualLookup("bar", "")
+
+	if err = c.Reindex(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	checkLookup("foo@example.org", created.ID)
+	checkLookup("bar@example.com", "")
+	checkManualLookup("foo", created.ID)
+	checkManualLookup("bar", "")
+}
+
+func newTestLookupStruct(userID, value, email string) (ts *testLookupStruct) {
+	ts = &testLookupStruct{Email: email}
+	ts.testStruct = makeTestStruct(userID, "contact_1", "group_1", value)
+	return
+}
+
+func TestMojura_UniqueRelationships(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := MakeOpts("test", testDir)
+	if opts.Source, err = kiroku.NewIOSource(testDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = New[*testStruct](opts, "users", Unique("contacts"), "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { testTeardown(c, t) }()
+
+	foo := makeTestStruct("user_1", "contact_1", "group_1", "foo")
+	bar := makeTestStruct("user_1", "contact_2", "group_1", "bar")
+
+	var created, other *testStruct
+	if created, err = c.New(&foo); err != nil {
+		t.Fatal(err)
+	}
+
+	if other, err = c.New(&bar); err != nil {
+		t.Fatal(err)
+	}
+
+	checkViolation := func(err error) {
+		uerr, ok := err.(*UniqueConstraintError)
+		switch {
+		case !ok:
+			t.Fatalf("invalid error, expected %T and received <%v>", uerr, err)
+		case uerr.Unwrap() != ErrUniqueConstraintViolation:
+			t.Fatalf("invalid wrapped error, expected <%v> and received <%v>", ErrUniqueConstraintViolation, uerr.Unwrap())
+		case uerr.RelationshipKey != "contacts" || uerr.RelationshipID != "contact_1" || uerr.EntryID != created.ID:
+			t.Fatalf("invalid error values: %+v", uerr)
+		}
+	}
+
+	dupe := makeTestStruct("user_2", "contact_1", "group_1", "dupe")
+	_, err = c.New(&dupe)
+	checkViolation(err)
+
+	_, err = c.Update(other.ID, func(ts *testStruct) (err error) {
+		ts.ContactID = "contact_1"
+		return
+	})
+	checkViolation(err)
+
+	// Non-unique relationships can still be shared, and an entry can keep its own unique IDs
+	if _, err = c.Update(created.ID, func(ts *testStruct) (err error) {
+		ts.Value = "foo foo"
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int64
+	if n, err = c.Count(nil); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("invalid count, expected %d and received %d", 2, n)
+	}
+
+	if _, err = c.Delete(created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Update(other.ID, func(ts *testStruct) (err error) {
+		ts.ContactID = "contact_1"
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMojura_TaggedRelationships(t *testing.T) {
+	var (
+		c   *Mojura[*testTaggedStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	opts := MakeOpts("test_tagged", testDir)
+	if _, err = New[*testTaggedStruct](opts, "users", "tags", "groups", Unique("emails")); err == nil {
+		t.Fatal("expected error for mismatched relationships and received nil")
+	}
+
+	if _, err = New[*testInvalidTaggedStruct](opts); err == nil {
+		t.Fatal("expected error for unsupported relationship field and received nil")
+	}
+
+	if c, err = New[*testTaggedStruct](opts); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	foo := testTaggedStruct{UserID: "user_1", Group: 1, Tags: []string{"a", "b"}, Email: "foo@example.com"}
+	bar := testTaggedStruct{UserID: "user_2", Group: 1, Tags: []string{"b"}, Email: "bar@example.com"}
+	if _, err = c.New(&foo); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.New(&bar); err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		filter   Filter
+		expected int64
+	}
+
+	tcs := []testcase{
+		{filter: filters.Match("users", "user_1"), expected: 1},
+		{filter: filters.Match("groups", "group_1"), expected: 2},
+		{filter: filters.Match("tags", "b"), expected: 2},
+		{filter: filters.Match("tags", "a"), expected: 1},
+		{filter: filters.Match("emails", "bar@example.com"), expected: 1},
+	}
+
+	for i, tc := range tcs {
+		var n int64
+		if n, err = c.Count(NewFilteringOpts(tc.filter)); err != nil {
+			t.Fatal(err)
+		}
+
+		if n != tc.expected {
+			t.Fatalf("invalid count, expected %d and received %d (test case #%d)", tc.expected, n, i)
+		}
+	}
+
+	dupe := testTaggedStruct{UserID: "user_3", Email: "foo@example.com"}
+	if _, err = c.New(&dupe); err == nil {
+		t.Fatal("expected unique constraint violation and received nil")
+	}
+}
+
+func TestMojura_Schema(t *testing.T) {
+	var err error
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	opts := MakeOpts("test_schema", testDir)
+
+	var v1 *Mojura[*testSchemaV1]
+	if v1, err = New[*testSchemaV1](opts); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, val := range []*testSchemaV1{
+		{UserID: "user_1", GroupID: "group_1", ContactID: "contact_1"},
+		{UserID: "user_2", GroupID: "group_1", ContactID: "contact_2"},
+	} {
+		if _, err = v1.New(val); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = v1.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.RelationshipRenames = map[string]string{"groups": "teams"}
+
+	var v2 *Mojura[*testSchemaV2]
+	if v2, err = New[*testSchemaV2](opts); err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		filter   Filter
+		expected int64
+	}
+
+	check := func(c interface {
+		Count(*FilteringOpts) (int64, error)
+	}, tcs []testcase) {
+		for i, tc := range tcs {
+			var n int64
+			if n, err = c.Count(NewFilteringOpts(tc.filter)); err != nil {
+				t.Fatalf("error counting (test case #%d): %v", i, err)
+			}
+
+			if n != tc.expected {
+				t.Fatalf("invalid count, expected %d and received %d (test case #%d)", tc.expected, n, i)
+			}
+		}
+	}
+
+	check(v2, []testcase{
+		{filter: filters.Match("users", "user_1"), expected: 1},
+		{filter: filters.Match("teams", "group_1"), expected: 2},
+		{filter: filters.Match("contacts", "contact_2"), expected: 1},
+	})
+
+	if _, _, err = v2.GetFilteredIDs(NewFilteringOpts(filters.Match("teams", "group_1"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = v2.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var v3 *Mojura[*testSchemaV3]
+	if v3, err = New[*testSchemaV3](opts); err != nil {
+		t.Fatal(err)
+	}
+	defer v3.Close()
+
+	check(v3, []testcase{
+		{filter: filters.Match("teams", "group_1"), expected: 2},
+		{filter: filters.Match("contacts", "contact_1"), expected: 1},
+	})
+
+	if err = v3.ReadTransaction(context.Background(), func(txn *Transaction[*testSchemaV3]) (err error) {
+		for _, key := range []string{"users", "groups"} {
+			if txn.txn.GetBucket(relationshipsBktKey).GetBucket([]byte(key)) != nil {
+				return fmt.Errorf("expected relationship bucket <%s> to be removed", key)
+			}
+		}
+
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMojura_Migrate(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	opts := MakeOpts("test_migrations", testDir)
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	var created []*testStruct
+	if created, err = c.NewMany(context.Background(), []*testStruct{
+		newTestStruct("user_1", "contact_1", "group_1", "foo"),
+		newTestStruct("user_1", "contact_1", "group_1", "bar"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	upgrader := func(fn func(map[string]any)) Upgrader {
+		return func(old []byte) (upgraded []byte, err error) {
+			var m map[string]any
+			if err = json.Unmarshal(old, &m); err != nil {
+				return
+			}
+
+			fn(m)
+			return json.Marshal(m)
+		}
+	}
+
+	opts.Upgraders = []Upgrader{
+		upgrader(func(m map[string]any) { m["value"] = strings.ToUpper(m["value"].(string)) }),
+		upgrader(func(m map[string]any) { m["userID"] = "user_2" }),
+	}
+
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.Close() }()
+
+	checkValues := func(expected ...string) {
+		var vals []*testStruct
+		if vals, _, err = c.GetFiltered(nil); err != nil {
+			t.Fatal(err)
+		}
+
+		for i, val := range vals {
+			if val.Value != expected[i] || val.UserID != "user_2" {
+				t.Fatalf("invalid entry, expected <%s> and <user_2> and received <%s> and <%s>", expected[i], val.Value, val.UserID)
+			}
+		}
+	}
+
+	var val *testStruct
+	if val, err = c.Get(created[0].ID); err != nil {
+		t.Fatal(err)
+	} else if val.Value != "FOO" {
+		t.Fatalf("invalid value, expected <%s> and received <%s>", "FOO", val.Value)
+	}
+
+	// Writing an entry stores it at the current schema version
+	if _, err = c.Update(created[1].ID, func(ts *testStruct) (err error) {
+		ts.Value = "baz"
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkValues("FOO", "baz")
+
+	var n int64
+	if n, err = c.Count(NewFilteringOpts(filters.Match("users", "user_2"))); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("invalid count, expected %d and received %d", 1, n)
+	}
+
+	if n, err = c.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("invalid number of migrated entries, expected %d and received %d", 1, n)
+	}
+
+	if n, err = c.Count(NewFilteringOpts(filters.Match("users", "user_2"))); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("invalid count, expected %d and received %d", 2, n)
+	}
+
+	checkValues("FOO", "baz")
+
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	checkValues("FOO", "baz")
+
+	if n, err = c.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("invalid number of migrated entries, expected %d and received %d", 0, n)
+	}
+}
+
+func TestMojura_New_with_IDGenerator(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := MakeOpts("test", testDir)
+	opts.IDGenerator = &ULIDGenerator{}
+	if opts.Source, err = kiroku.NewIOSource(testDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	var expected []string
+	for i := 0; i < 5; i++ {
+		var created *testStruct
+		if created, err = c.New(newTestStruct("user_1", "contact_1", "group_1", strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(created.ID) != 26 {
+			t.Fatalf("invalid entry ID <%s>", created.ID)
+		}
+
+		expected = append(expected, created.ID)
+	}
+
+	var ids []string
+	if ids, _, err = c.GetFilteredIDs(NewFilteringOpts(filters.Match("users", "user_1"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(expected, ids) {
+		t.Fatalf("invalid IDs, expected %v and received %v", expected, ids)
+	}
+}
+
+func TestMojura_Promote(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	defer os.RemoveAll(testDir)
+	for _, dir := range []string{"source", "primary", "mirror"} {
+		if err = os.MkdirAll(path.Join(testDir, dir), 0744); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var src *kiroku.IOSource
+	if src, err = kiroku.NewIOSource(path.Join(testDir, "source")); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := MakeOpts("test", path.Join(testDir, "primary"))
+	opts.Source = src
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = c.New(newTestStruct("user_1", "contact_1", "group_1", strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = c.Delete("00000002"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the primary will export the remaining history to the source
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mirrorOpts := MakeOpts("test", path.Join(testDir, "mirror"))
+	mirrorOpts.Source = src
+	mirrorOpts.IsMirror = true
+
+	var mirror *Mojura[*testStruct]
+	if mirror, err = New[*testStruct](mirrorOpts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { mirror.Close() }()
+
+	ctx := context.Background()
+	if err = mirror.Demote(ctx); err != ErrAlreadyMirror {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrAlreadyMirror, err)
+	}
+
+	if err = mirror.Promote(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = mirror.Promote(ctx); err != ErrAlreadyPrimary {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrAlreadyPrimary, err)
+	}
+
+	var n int64
+	if n, err = mirror.Count(NewFilteringOpts(filters.Match("users", "user_1"))); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("invalid count, expected %d and received %d", 2, n)
+	}
+
+	// The index is restored from the imported history, so deleted entry IDs are not reused
+	var created *testStruct
+	if created, err = mirror.New(newTestStruct("user_1", "contact_1", "group_1", "3")); err != nil {
+		t.Fatal(err)
+	} else if created.ID != "00000003" {
+		t.Fatalf("invalid entry ID, expected <%s> and received <%s>", "00000003", created.ID)
+	}
+
+	if err = mirror.Demote(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = mirror.New(newTestStruct("user_1", "contact_1", "group_1", "4")); err != ErrMirrorCannotPerformWriteActions {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrMirrorCannotPerformWriteActions, err)
+	}
+
+	if _, err = mirror.Get(created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = mirror.Promote(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes made during a demotion either succeed or are rejected as mirror writes
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, err := mirror.New(newTestStruct("user_2", "contact_1", "group_1", strconv.Itoa(j)))
+				if err == ErrMirrorCannotPerformWriteActions {
+					return
+				} else if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	if err = mirror.Demote(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err = range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestMojura_ReplicationStatus(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	defer os.RemoveAll(testDir)
+	for _, dir := range []string{"source", "primary", "mirror"} {
+		if err = os.MkdirAll(path.Join(testDir, dir), 0744); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var src *kiroku.IOSource
+	if src, err = kiroku.NewIOSource(path.Join(testDir, "source")); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := MakeOpts("test", path.Join(testDir, "primary"))
+	opts.Source = src
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = c.New(newTestStruct("user_1", "contact_1", "group_1", strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The position of the last write is waited on by the mirror
+	position := c.Position()
+	if position != 3 {
+		t.Fatalf("invalid position, expected %d and received %d", 3, position)
+	}
+
+	// Closing the primary will export the remaining history to the source
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mirrorOpts := MakeOpts("test", path.Join(testDir, "mirror"))
+	mirrorOpts.Source = src
+	mirrorOpts.IsMirror = true
+
+	var mirror *Mojura[*testStruct]
+	if mirror, err = New[*testStruct](mirrorOpts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer mirror.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if err = mirror.WaitUntilCaughtUp(ctx, position); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = mirror.Get("00000002"); err != nil {
+		t.Fatal(err)
+	}
+
+	status := mirror.ReplicationStatus()
+	switch {
+	case status.LastImportedPosition != position:
+		t.Fatalf("invalid last imported position, expected %d and received %d", position, status.LastImportedPosition)
+	case status.LastImportedAt.IsZero():
+		t.Fatal("invalid last imported at, expected a value")
+	case status.BlocksApplied != 3:
+		t.Fatalf("invalid blocks applied, expected %d and received %d", 3, status.BlocksApplied)
+	case status.LastError != nil:
+		t.Fatalf("invalid last error, expected nil and received %v", status.LastError)
+	case status.LastImportCompletedAt.IsZero():
+		t.Fatal("invalid last import completed at, expected a value")
+	}
+
+	timeoutCtx, timeoutCancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer timeoutCancel()
+	if err = mirror.WaitUntilCaughtUp(timeoutCtx, position+1); err != context.DeadlineExceeded {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", context.DeadlineExceeded, err)
+	}
+}
+
+func TestMojura_RestoreTo(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	defer os.RemoveAll(testDir)
+	for _, dir := range []string{"source", "primary"} {
+		if err = os.MkdirAll(path.Join(testDir, dir), 0744); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var src *kiroku.IOSource
+	if src, err = kiroku.NewIOSource(path.Join(testDir, "source")); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := MakeOpts("test", path.Join(testDir, "primary"))
+	opts.Source = src
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err = c.New(newTestStruct("user_1", "contact_1", "group_1", strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	restorePoint := time.Now()
+	if _, err = c.Put("00000000", newTestStruct("user_2", "contact_1", "group_1", "corrupted")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Delete("00000001"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the primary will export the remaining history to the source
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.Close() }()
+
+	var view *Mojura[*testStruct]
+	if view, err = c.At(restorePoint); err != nil {
+		t.Fatal(err)
+	}
+
+	var val *testStruct
+	if val, err = view.Get("00000000"); err != nil {
+		t.Fatal(err)
+	} else if val.Value != "0" {
+		t.Fatalf("invalid value, expected <%s> and received <%s>", "0", val.Value)
+	}
+
+	var n int64
+	if n, err = view.Count(NewFilteringOpts(filters.Match("users", "user_1"))); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("invalid count, expected %d and received %d", 2, n)
+	}
+
+	if _, err = view.New(newTestStruct("user_1", "contact_1", "group_1", "2")); err != ErrMirrorCannotPerformWriteActions {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrMirrorCannotPerformWriteActions, err)
+	}
+
+	viewDir := view.viewDir
+	if err = view.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(viewDir); !os.IsNotExist(err) {
+		t.Fatalf("expected view directory to be removed, received <%v>", err)
+	}
+
+	ctx := context.Background()
+	restoreDir := path.Join(testDir, "restored")
+	if err = c.RestoreToBlock(ctx, restoreDir, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.RestoreToBlock(ctx, restoreDir, 1); err != ErrRestoreTargetExists {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrRestoreTargetExists, err)
+	}
+
+	restoredOpts := MakeOpts("test", restoreDir)
+	restoredOpts.IsMirror = true
+
+	var restored *Mojura[*testStruct]
+	if restored, err = New[*testStruct](restoredOpts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+
+	if _, err = restored.Get("00000000"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = restored.Get("00000001"); err != ErrEntryNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryNotFound, err)
+	}
+}
+
+func TestMojura_RestoreTo_within_chunk(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	defer os.RemoveAll(testDir)
+	for _, dir := range []string{"source", "primary"} {
+		if err = os.MkdirAll(path.Join(testDir, dir), 0744); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var src *kiroku.IOSource
+	if src, err = kiroku.NewIOSource(path.Join(testDir, "source")); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := MakeOpts("test", path.Join(testDir, "primary"))
+	opts.Source = src
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.Close() }()
+
+	ctx := context.Background()
+	var restorePoint time.Time
+	// Both entries are written within the same chunk
+	if err = c.Transaction(ctx, func(txn *Transaction[*testStruct]) (err error) {
+		if _, err = txn.New(newTestStruct("user_1", "contact_1", "group_1", "0")); err != nil {
+			return
+		}
+
+		restorePoint = time.Now()
+		time.Sleep(time.Millisecond)
+		_, err = txn.New(newTestStruct("user_1", "contact_1", "group_1", "1"))
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the primary will export the remaining history to the source
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	var view *Mojura[*testStruct]
+	if view, err = c.At(restorePoint); err != nil {
+		t.Fatal(err)
+	}
+	defer view.Close()
+
+	if _, err = view.Get("00000000"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = view.Get("00000001"); err != ErrEntryNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryNotFound, err)
+	}
+}
+
+func TestMojura_History(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	opts := MakeOpts("test_history", testDir)
+	opts.IndexHistory = true
+	if opts.Source, err = kiroku.NewIOSource(testDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.Close() }()
+
+	ctx := context.Background()
+	if _, err = c.History(ctx, "00000000"); err != ErrEntryNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryNotFound, err)
+	}
+
+	var created *testStruct
+	if created, err = c.New(newTestStruct("user_1", "contact_1", "group_1", "foo")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.Transaction(ctx, func(txn *Transaction[*testStruct]) (err error) {
+		txn.SetActor("actor_1")
+		txn.SetReason("renamed")
+		_, err = txn.Put(created.ID, newTestStruct("user_1", "contact_1", "group_1", "bar"))
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Delete(created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var revisions []Revision[*testStruct]
+	if revisions, err = c.History(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	type testcase struct {
+		actionType action.Type
+		value      string
+		actorID    string
+		reason     string
+	}
+
+	tcs := []testcase{
+		{actionType: action.TypeWrite, value: "foo"},
+		{actionType: action.TypeWrite, value: "bar", actorID: "actor_1", reason: "renamed"},
+		{actionType: action.TypeDelete, value: "bar"},
+	}
+
+	if len(revisions) != len(tcs) {
+		t.Fatalf("invalid number of revisions, expected %d and received %d", len(tcs), len(revisions))
+	}
+
+	for i, tc := range tcs {
+		r := revisions[i]
+		switch {
+		case r.Type != tc.actionType:
+			t.Fatalf("invalid type for revision %d, expected %v and received %v", i, tc.actionType, r.Type)
+		case r.Value.Value != tc.value:
+			t.Fatalf("invalid value for revision %d, expected <%s> and received <%s>", i, tc.value, r.Value.Value)
+		case r.Value.ID != created.ID:
+			t.Fatalf("invalid ID for revision %d, expected <%s> and received <%s>", i, created.ID, r.Value.ID)
+		case r.Position != uint64(i+1):
+			t.Fatalf("invalid position for revision %d, expected %d and received %d", i, i+1, r.Position)
+		case r.ActorID != tc.actorID:
+			t.Fatalf("invalid actor ID for revision %d, expected <%s> and received <%s>", i, tc.actorID, r.ActorID)
+		case r.Reason != tc.reason:
+			t.Fatalf("invalid reason for revision %d, expected <%s> and received <%s>", i, tc.reason, r.Reason)
+		case i > 0 && r.Timestamp.Before(revisions[i-1].Timestamp):
+			t.Fatalf("invalid timestamp for revision %d, %v is before %v", i, r.Timestamp, revisions[i-1].Timestamp)
+		}
+	}
+}
+
+func TestMojura_History_not_indexed(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	if _, err = c.History(context.Background(), "00000000"); err != ErrHistoryNotIndexed {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrHistoryNotIndexed, err)
+	}
+}
+
+func TestMojura_action_metadata(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	var blocks testBlockWriter
+	start := time.Now()
+	if err = c.db.Transaction(func(btxn backend.Transaction) (err error) {
+		_, err = c.runTransaction(context.Background(), btxn, &blocks, func(txn *Transaction[*testStruct]) (err error) {
+			var created *testStruct
+			if created, err = txn.New(newTestStruct("user_1", "contact_1", "group_1", "foo")); err != nil {
+				return
+			}
+
+			txn.SetActor("user_9")
+			txn.SetRequestID("request_1")
+			txn.SetReason("cleanup")
+			_, err = txn.Delete(created.ID)
+			return
+		})
+
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocks written before metadata existed must remain readable
+	var legacy bytes.Buffer
+	if err = enkodo.NewWriter(&legacy).Encode(&testLegacyAction{Type: action.TypeWrite, Key: []byte("00000001"), Value: []byte("{}")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = blocks.Write(legacy.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	var r *kiroku.Reader
+	if r, err = blocks.reader(); err != nil {
+		t.Fatal(err)
+	}
+
+	var as []action.Action
+	ar := action.MakeReader(r)
+	if err = ar.ForEach(func(a action.Action) (err error) {
+		as = append(as, a)
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(as) != 3 {
+		t.Fatalf("invalid number of actions, expected %d and received %d", 3, len(as))
+	}
+
+	if md := as[0].Metadata; md.ActorID != "" || md.Timestamp.Before(start) {
+		t.Fatalf("invalid metadata for first action, received %+v", md)
+	}
+
+	md := as[1].Metadata
+	switch {
+	case md.ActorID != "user_9":
+		t.Fatalf("invalid actor ID, expected <%s> and received <%s>", "user_9", md.ActorID)
+	case md.RequestID != "request_1":
+		t.Fatalf("invalid request ID, expected <%s> and received <%s>", "request_1", md.RequestID)
+	case md.Reason != "cleanup":
+		t.Fatalf("invalid reason, expected <%s> and received <%s>", "cleanup", md.Reason)
+	case md.Timestamp.Before(as[0].Metadata.Timestamp):
+		t.Fatalf("invalid timestamp, %v is before %v", md.Timestamp, as[0].Metadata.Timestamp)
+	}
+
+	switch legacy := as[2]; {
+	case !legacy.Metadata.IsEmpty():
+		t.Fatalf("invalid metadata for legacy action, expected empty metadata and received %+v", legacy.Metadata)
+	case string(legacy.Key) != "00000001" || string(legacy.Value) != "{}":
+		t.Fatalf("invalid legacy action, received <%s> <%s>", legacy.Key, legacy.Value)
+	}
+}
+
+func TestMojura_SoftDelete(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	opts := MakeOpts("test_soft_delete", testDir)
+	opts.SoftDelete = true
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.Close() }()
+
+	var foo, bar *testStruct
+	if foo, err = c.New(newTestStruct("user_1", "contact_1", "group_1", "foo")); err != nil {
+		t.Fatal(err)
+	}
+
+	if bar, err = c.New(newTestStruct("user_2", "contact_1", "group_1", "bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.SetLookup("username", "foo", foo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Delete(foo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	testSoftDeleteIDs(t, c, bar.ID)
+	if _, err = c.Get(foo.ID); err != ErrEntryNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryNotFound, err)
+	}
+
+	if _, err = c.GetByLookup("username", "foo"); err != ErrLookupNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrLookupNotFound, err)
+	}
+
+	var restored *testStruct
+	if restored, err = c.Restore(foo.ID); err != nil {
+		t.Fatal(err)
+	} else if restored.Value != "foo" {
+		t.Fatalf("invalid value, expected <%s> and received <%s>", "foo", restored.Value)
+	} else if restored.Version != foo.Version+1 {
+		t.Fatalf("invalid version, expected %d and received %d", foo.Version+1, restored.Version)
+	}
+
+	var found *testStruct
+	if found, err = c.GetByLookup("username", "foo"); err != nil {
+		t.Fatal(err)
+	} else if found.ID != foo.ID {
+		t.Fatalf("invalid lookup, expected <%s> and received <%s>", foo.ID, found.ID)
+	}
+
+	testSoftDeleteIDs(t, c, foo.ID, bar.ID)
+	if _, err = c.Restore(foo.ID); err != ErrEntryNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryNotFound, err)
+	}
+
+	if _, err = c.Delete(foo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int64
+	if n, err = c.Purge(time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("invalid number of purged entries, expected %d and received %d", 0, n)
+	}
+
+	if n, err = c.Purge(time.Now()); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("invalid number of purged entries, expected %d and received %d", 1, n)
+	}
+
+	if _, err = c.Restore(foo.ID); err != ErrEntryNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryNotFound, err)
+	}
+
+	testSoftDeleteIDs(t, c, bar.ID)
+}
+
+func TestMojura_SoftDelete_not_enabled(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	var created *testStruct
+	if created, err = c.New(newTestStruct("user_1", "contact_1", "group_1", "foo")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Delete(created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Restore(created.ID); err != ErrSoftDeleteNotEnabled {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrSoftDeleteNotEnabled, err)
+	}
+
+	if _, err = c.Purge(time.Now()); err != ErrSoftDeleteNotEnabled {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrSoftDeleteNotEnabled, err)
+	}
+}
+
+func testSoftDeleteIDs(t *testing.T, c *Mojura[*testStruct], expected ...string) {
+	o := NewFilteringOpts(filters.Match("groups", "group_1"))
+	ids, _, err := c.GetFilteredIDs(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(ids, expected) {
+		t.Fatalf("invalid filtered IDs, expected %v and received %v", expected, ids)
+	}
+
+	var n int64
+	if n, err = c.Count(o); err != nil {
+		t.Fatal(err)
+	} else if n != int64(len(expected)) {
+		t.Fatalf("invalid count, expected %d and received %d", len(expected), n)
+	}
+}
+
+func TestMojura_Subscribe(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var changes <-chan Change[*testStruct]
+	if changes, err = c.Subscribe(ctx, NewFilteringOpts(filters.Match("users", "user_1"))); err != nil {
+		t.Fatal(err)
+	}
+
+	var created *testStruct
+	if created, err = c.New(newTestStruct("user_1", "contact_1", "group_1", "foo")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.New(newTestStruct("user_2", "contact_1", "group_1", "bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The update moves the entry out of the filter, which is matched by the previous value
+	if _, err = c.Put(created.ID, newTestStruct("user_2", "contact_1", "group_1", "foo_updated")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Delete(created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	type expectedChange struct {
+		position   uint64
+		actionType action.Type
+		value      string
+		previous   string
+	}
+
+	// The delete is not matched, as the entry had already left the filter
+	expected := []expectedChange{
+		{position: 1, actionType: action.TypeWrite, value: "foo"},
+		{position: 3, actionType: action.TypeWrite, value: "foo_updated", previous: "foo"},
+	}
+
+	receive := func(changes <-chan Change[*testStruct], expected []expectedChange) {
+		for _, e := range expected {
+			select {
+			case change := <-changes:
+				switch {
+				case change.Position != e.position:
+					t.Fatalf("invalid position, expected %d and received %d", e.position, change.Position)
+				case change.Type != e.actionType:
+					t.Fatalf("invalid type, expected <%v> and received <%v>", e.actionType, change.Type)
+				case change.EntryID != created.ID:
+					t.Fatalf("invalid entry ID, expected <%s> and received <%s>", created.ID, change.EntryID)
+				case change.Value.Value != e.value:
+					t.Fatalf("invalid value, expected <%s> and received <%s>", e.value, change.Value.Value)
+				case e.previous == "" && change.Previous != nil:
+					t.Fatalf("invalid previous value, expected none and received <%s>", change.Previous.Value)
+				case e.previous != "" && (change.Previous == nil || change.Previous.Value != e.previous):
+					t.Fatalf("invalid previous value, expected <%s> and received %+v", e.previous, change.Previous)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for change")
+			}
+		}
+	}
+
+	receive(changes, expected)
+
+	var resumed <-chan Change[*testStruct]
+	if resumed, err = c.SubscribeFrom(ctx, NewFilteringOpts(filters.Match("users", "user_1")), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	receive(resumed, expected[1:])
+
+	if _, err = c.SubscribeFrom(ctx, nil, 5); err != ErrPositionUnavailable {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrPositionUnavailable, err)
+	}
+
+	if _, err = c.Subscribe(ctx, NewFilteringOpts(filters.Match("invalid", "foo"))); err != ErrRelationshipNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrRelationshipNotFound, err)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-changes:
+		if ok {
+			t.Fatal("expected subscription to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for subscription to close")
+	}
+}
+
+func TestMojura_SubscribeFrom_history(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { testTeardown(c, t) }()
+
+	var created *testStruct
+	if created, err = c.New(newTestStruct("user_1", "contact_1", "group_1", "foo")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.New(newTestStruct("user_2", "contact_1", "group_1", "bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Delete(created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing exports the history to the Source and clears the in-memory backlog
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := MakeOpts("test", testDir)
+	if opts.Source, err = kiroku.NewIOSource(testDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var changes <-chan Change[*testStruct]
+	if changes, err = c.SubscribeFrom(ctx, nil, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var other <-chan Change[*testStruct]
+	if other, err = c.Subscribe(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var updated *testStruct
+	if updated, err = c.New(newTestStruct("user_3", "contact_1", "group_1", "baz")); err != nil {
+		t.Fatal(err)
+	}
+
+	receive := func(changes <-chan Change[*testStruct]) (change Change[*testStruct]) {
+		select {
+		case change = <-changes:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for change")
+		}
+
+		return
+	}
+
+	expected := []struct {
+		position   uint64
+		actionType action.Type
+		value      string
+	}{
+		{position: 2, actionType: action.TypeWrite, value: "bar"},
+		{position: 3, actionType: action.TypeDelete, value: "foo"},
+		{position: 4, actionType: action.TypeWrite, value: "baz"},
+	}
+
+	var received Change[*testStruct]
+	for _, e := range expected {
+		switch received = receive(changes); {
+		case received.Position != e.position:
+			t.Fatalf("invalid position, expected %d and received %d", e.position, received.Position)
+		case received.Type != e.actionType:
+			t.Fatalf("invalid type, expected <%v> and received <%v>", e.actionType, received.Type)
+		case received.Value.Value != e.value:
+			t.Fatalf("invalid value, expected <%s> and received <%s>", e.value, received.Value.Value)
+		}
+	}
+
+	// Values are decoded once for each change, separate from the value of the writer
+	if change := receive(other); change.Value == updated || change.Value.Value != updated.Value {
+		t.Fatal("expected changes to contain a decoded copy of the value")
+	}
+
+	if _, err = c.SubscribeFrom(ctx, nil, 5); err != ErrPositionUnavailable {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrPositionUnavailable, err)
+	}
+}
+
+func TestMojura_Subscribe_slow(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := MakeOpts("test_subscribe_slow", testDir)
+	opts.SubscriptionBufferSize = 2
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { testTeardown(c, t) }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var changes <-chan Change[*testStruct]
+	if changes, err = c.Subscribe(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes do not wait for a subscriber which is not receiving
+	for i := 0; i < 5; i++ {
+		if _, err = c.New(newTestStruct("user_1", "contact_1", "group_1", strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var n int
+	for {
+		select {
+		case _, ok := <-changes:
+			if ok {
+				n++
+				continue
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for subscription to close")
+		}
+
+		break
+	}
+
+	if n != opts.SubscriptionBufferSize {
+		t.Fatalf("invalid number of changes, expected %d and received %d", opts.SubscriptionBufferSize, n)
+	}
+}
+
+func TestMojura_Rekey(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+
+	opts := MakeOpts("test_rekey", testDir)
+	opts.IndexLength = 1
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { c.Close() }()
+
+	ctx := context.Background()
+	vals := make([]*testStruct, 0, 10)
+	for i := 0; i < 10; i++ {
+		vals = append(vals, newTestStruct("user_1", "contact_1", "group_1", strconv.Itoa(i)))
+	}
+
+	if _, err = c.NewMany(ctx, vals); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.New(newTestStruct("user_1", "contact_1", "group_1", "10")); err != ErrIndexLengthExceeded {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrIndexLengthExceeded, err)
+	}
+
+	if _, err = c.Rekey(ctx, 1); err != ErrInvalidIndexLength {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrInvalidIndexLength, err)
+	}
+
+	var n int64
+	if n, err = c.Rekey(ctx, 3); err != nil {
+		t.Fatal(err)
+	} else if n != 10 {
+		t.Fatalf("invalid number of rekeyed entries, expected %d and received %d", 10, n)
+	}
+
+	var created *testStruct
+	if created, err = c.New(newTestStruct("user_1", "contact_1", "group_1", "10")); err != nil {
+		t.Fatal(err)
+	} else if created.ID != "010" {
+		t.Fatalf("invalid entry ID, expected <%s> and received <%s>", "010", created.ID)
+	}
+
+	// A rekey which is rolled back does not switch the ID generator
+	errRollback := errors.Error("rollback")
+	if err = c.Transaction(ctx, func(txn *Transaction[*testStruct]) (err error) {
+		if _, err = txn.rekey(txn.getIDGenerator().(*SequentialIDGenerator), NewSequentialIDGenerator(5)); err != nil {
+			return
+		}
+
+		return errRollback
+	}); err != errRollback {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", errRollback, err)
+	}
+
+	if g := c.getIDGenerator().(*SequentialIDGenerator); g.IndexLength() != 3 {
+		t.Fatalf("invalid index length, expected %d and received %d", 3, g.IndexLength())
+	}
+
+	check := func() {
+		var vals []*testStruct
+		if vals, _, err = c.GetFiltered(NewFilteringOpts(filters.Match("users", "user_1"))); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(vals) != 11 {
+			t.Fatalf("invalid number of entries, expected %d and received %d", 11, len(vals))
+		}
+
+		for i, val := range vals {
+			if expected := fmt.Sprintf("%03d", i); val.ID != expected || val.Value != strconv.Itoa(i) {
+				t.Fatalf("invalid entry, expected <%s> and received <%s> (%s)", expected, val.ID, val.Value)
+			}
+		}
+	}
+
+	check()
+
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	check()
+
+	if created, err = c.New(newTestStruct("user_2", "contact_1", "group_1", "11")); err != nil {
+		t.Fatal(err)
+	} else if created.ID != "011" {
+		t.Fatalf("invalid entry ID, expected <%s> and received <%s>", "011", created.ID)
+	}
+}
+
+func TestMojura_Update(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	foobar := makeTestStruct("user_1", "contact_1", "group_1", "FOO FOO")
+
+	var created *testStruct
+	if created, err = c.New(&foobar); err != nil {
+		t.Fatal(err)
+	}
+
+	foobar.ID = created.ID
+	foobar.Value = "FOO FOO"
+
+	var updated *testStruct
+	if updated, err = c.Update(created.ID, func(e *testStruct) (err error) {
+		*e = foobar
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testCheck(&foobar, updated); err != nil {
+		t.Fatal(err)
+	}
+
+	var fb *testStruct
+	if fb, err = c.Get(created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testCheck(&foobar, fb); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMojura_ForEach(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	foobar := makeTestStruct("user_1", "contact_1", "group_1", "FOO FOO")
+
+	if _, err = c.New(&foobar); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.New(&foobar); err != nil {
+		t.Fatal(err)
+	}
+
+	var cnt int
+	if err = c.ForEach(func(key string, v *testStruct) (err error) {
+		// We are not checking ID correctness in this test
+		foobar.ID = v.ID
+
+		if err = testCheck(&foobar, v); err != nil {
+			t.Fatal(err)
+		}
+
+		cnt++
+		return
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if cnt != 2 {
+		t.Fatalf("invalid number of entries, expected %d and received %d", 2, cnt)
+	}
+}
+
+func TestMojura_All(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	entries := []*testStruct{
+		newTestStruct("user_1", "contact_1", "group_1", "0"),
+		newTestStruct("user_2", "contact_1", "group_1", "1"),
+		newTestStruct("user_1", "contact_1", "group_1", "2"),
+		newTestStruct("user_1", "contact_1", "group_1", "3"),
+	}
+
+	for i, entry := range entries {
+		if entries[i], err = c.New(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	opts := NewFilteringOpts(filters.Match("users", "user_1"))
+
+	var values []string
+	for entryID, v := range c.All(ctx, opts, &err) {
+		if entryID != v.ID {
+			t.Fatalf("invalid entry ID, expected <%s> and received <%s>", v.ID, entryID)
+		}
+
+		values = append(values, v.Value)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"0", "2", "3"}; !slices.Equal(expected, values) {
+		t.Fatalf("invalid values, expected %v and received %v", expected, values)
+	}
+
+	var ids []string
+	for entryID := range c.IDs(ctx, opts, &err) {
+		// The read transaction is released before entries are yielded, so writes can be made
+		if _, err = c.Update(entryID, func(v *testStruct) error {
+			v.Value += "_updated"
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if ids = append(ids, entryID); len(ids) == 2 {
+			break
+		}
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{entries[0].ID, entries[2].ID}; !slices.Equal(expected, ids) {
+		t.Fatalf("invalid IDs, expected %v and received %v", expected, ids)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for range c.All(cctx, nil, &err) {
+		cancel()
+	}
+
+	if err != context.Canceled {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", context.Canceled, err)
+	}
+
+	for entryID := range c.IDs(ctx, NewFilteringOpts(filters.Match("unknown", "foo")), &err) {
+		t.Fatalf("unexpected iteration of <%s>", entryID)
+	}
+
+	if err == nil {
+		t.Fatal("expected error for unknown relationship and received nil")
+	}
+}
+
+func TestMojura_All_pages(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	ctx := context.Background()
+	vals := make([]*testStruct, 0, iterationPageSize*2+1)
+	for i := 0; i < cap(vals); i++ {
+		vals = append(vals, newTestStruct("user_1", "contact_1", "group_1", strconv.Itoa(i)))
+	}
+
+	var created []*testStruct
+	if created, err = c.NewMany(ctx, vals); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []string
+	for _, v := range created {
+		expected = append(expected, v.ID)
+	}
+
+	for _, reverse := range []bool{false, true} {
+		o := NewFilteringOpts()
+		o.Reverse = reverse
+		if reverse {
+			slices.Reverse(expected)
+		}
+
+		var ids []string
+		for entryID := range c.IDs(ctx, o, &err) {
+			if len(ids) == iterationPageSize-1 {
+				// Removing the last entry of a page does not skip the first entry of the next page
+				if _, err = c.Delete(entryID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ids = append(ids, entryID)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(expected, ids) {
+			t.Fatalf("invalid IDs, expected %v and received %v", expected, ids)
+		}
+
+		expected = slices.Delete(expected, iterationPageSize-1, iterationPageSize)
+	}
+
+	var n int
+	for entryID, v := range c.All(ctx, nil, &err) {
+		if entryID != expected[len(expected)-1-n] {
+			t.Fatalf("invalid entry ID, expected <%s> and received <%s>", expected[len(expected)-1-n], entryID)
+		}
+
+		if v.ID != entryID {
+			t.Fatalf("invalid entry, expected <%s> and received <%s>", entryID, v.ID)
+		}
+
+		n++
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != len(expected) {
+		t.Fatalf("invalid number of entries, expected %d and received %d", len(expected), n)
+	}
+}
+
+func TestMojura_Ctx(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	foobar := makeTestStruct("user_1", "contact_1", "group_1", "FOO FOO")
+	for i := 0; i < 3; i++ {
+		if _, err = c.NewCtx(context.Background(), &foobar); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var cnt int
+	if err = c.ForEachCtx(ctx, func(entryID string, val *testStruct) (err error) {
+		cnt++
+		cancel()
+		return
+	}, nil); err != context.Canceled {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", context.Canceled, err)
+	}
+
+	if cnt != 1 {
+		t.Fatalf("invalid number of iterations, expected %d and received %d", 1, cnt)
+	}
+
+	if _, _, err = c.GetFilteredCtx(ctx, NewFilteringOpts(filters.Match("users", "user_1"))); err != context.Canceled {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", context.Canceled, err)
+	}
+
+	if _, err = c.NewCtx(ctx, &foobar); err != context.Canceled {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", context.Canceled, err)
+	}
+
+	var n int64
+	if n, err = c.Count(nil); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("invalid count, expected %d and received %d", 3, n)
+	}
+}
+
+func TestMojura_NewMany(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	foobar := makeTestStruct("user_1", "contact_1", "group_1", "foo")
+	if _, err = c.New(&foobar); err != nil {
+		t.Fatal(err)
+	}
+
+	vals := []*testStruct{
+		newTestStruct("user_1", "contact_1", "group_1", "0"),
+		newTestStruct("user_2", "contact_1", "group_1", "1"),
+		newTestStruct("user_1", "contact_2", "group_1", "2"),
+	}
+
+	ctx := context.Background()
+
+	var created []*testStruct
+	if created, err = c.NewMany(ctx, vals); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, val := range created {
+		if expected := fmt.Sprintf("%08d", i+1); val.ID != expected {
+			t.Fatalf("invalid ID, expected <%s> and received <%s>", expected, val.ID)
+		}
+
+		if val.Value != vals[i].Value {
+			t.Fatalf("invalid value, expected <%s> and received <%s>", vals[i].Value, val.Value)
+		}
+	}
+
+	var n int64
+	if n, err = c.Count(NewFilteringOpts(filters.Match("users", "user_1"))); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("invalid count, expected %d and received %d", 3, n)
+	}
+
+	var next *testStruct
+	if next, err = c.New(&foobar); err != nil {
+		t.Fatal(err)
+	} else if next.ID != "00000004" {
+		t.Fatalf("invalid ID, expected <%s> and received <%s>", "00000004", next.ID)
+	}
+
+	var updated map[string]*testStruct
+	if updated, err = c.PutMany(ctx, map[string]*testStruct{
+		"foo":   newTestStruct("user_3", "contact_1", "group_1", "foo"),
+		"bar":   newTestStruct("user_3", "contact_1", "group_1", "bar"),
+		next.ID: newTestStruct("user_3", "contact_1", "group_1", "next"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(updated) != 3 || updated["foo"].ID != "foo" {
+		t.Fatalf("invalid updated entries: %v", updated)
+	}
+
+	if n, err = c.Count(NewFilteringOpts(filters.Match("users", "user_3"))); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("invalid count, expected %d and received %d", 3, n)
+	}
+}
+
+func TestMojura_DeleteFiltered(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	entries := []*testStruct{
+		newTestStruct("user_1", "contact_1", "group_1", "0"),
+		newTestStruct("user_2", "contact_1", "group_2", "1"),
+		newTestStruct("user_1", "contact_1", "group_1", "2"),
+		newTestStruct("user_3", "contact_2", "group_1", "3"),
+	}
+
+	for i, entry := range entries {
+		if entries[i], err = c.New(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+
+	var n int64
+	if n, err = c.UpdateFiltered(ctx, NewFilteringOpts(filters.Match("users", "user_1")), func(ts *testStruct) (err error) {
+		ts.GroupID = "group_2"
+		return
+	}); err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("invalid number of updated entries, expected %d and received %d", 2, n)
+	}
+
+	if n, err = c.Count(NewFilteringOpts(filters.Match("groups", "group_2"))); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("invalid count, expected %d and received %d", 3, n)
+	}
+
+	if n, err = c.UpdateFiltered(ctx, nil, func(ts *testStruct) (err error) {
+		return Break
+	}); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("invalid number of updated entries, expected %d and received %d", 0, n)
+	}
+
+	if n, err = c.DeleteFiltered(ctx, NewFilteringOpts(filters.Match("groups", "group_2"))); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("invalid number of deleted entries, expected %d and received %d", 3, n)
+	}
+
+	var ids []string
+	if ids, _, err = c.GetFilteredIDs(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 1 || ids[0] != entries[3].ID {
+		t.Fatalf("invalid IDs, expected %v and received %v", []string{entries[3].ID}, ids)
+	}
+
+	if n, err = c.Count(NewFilteringOpts(filters.Match("contacts", "contact_1"))); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("invalid count, expected %d and received %d", 0, n)
+	}
+}
+
+func TestMojura_Create(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(c, t)
+
+	foo := makeTestStruct("user_1", "contact_1", "group_1", "foo")
+	if _, err = c.Create("foo", &foo); err != nil {
+		t.Fatal(err)
+	}
+
+	bar := makeTestStruct("user_2", "contact_1", "group_1", "bar")
+	if _, err = c.Create("foo", &bar); err != ErrEntryExists {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryExists, err)
+	}
+
+	var val *testStruct
+	if val, err = c.Get("foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testCheck(&foo, val); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMojura_Upsert(t *testing.T) {
+	var (
+		c   *Mojura[*testStruct]
+		err error
+	)
+
+	if c, err = testInit(); err != nil {
+		t.Fatal(err)
 	}
+	defer testTeardown(c, t)
 
-	for _, entry := range entries {
-		if _, err = c.New(&entry); err != nil {
+	increment := func(existing *testStruct, found bool) (out *testStruct, err error) {
+		if !found {
+			val := makeTestStruct("user_1", "contact_1", "group_1", "1")
+			return &val, nil
+		}
+
+		existing.Value += "1"
+		return existing, nil
+	}
+
+	var val *testStruct
+	for i := 0; i < 3; i++ {
+		if val, err = c.Upsert("counter", increment); err != nil {
 			t.Fatal(err)
 		}
 	}
 
-	if err = runCases(createCases); err != nil {
+	if val.Value != "111" {
+		t.Fatalf("invalid value, expected <%s> and received <%s>", "111", val.Value)
+	}
+
+	var ids []string
+	if ids, _, err = c.GetFilteredIDs(NewFilteringOpts(filters.Match("users", "user_1"))); err != nil {
 		t.Fatal(err)
 	}
+
+	if len(ids) != 1 || ids[0] != "counter" {
+		t.Fatalf("invalid IDs, expected %v and received %v", []string{"counter"}, ids)
+	}
 }
 
-func TestMojura_AppendFilteredIDs(t *testing.T) {
+func TestMojura_PutIfVersion(t *testing.T) {
 	var (
 		c   *Mojura[*testStruct]
 		err error
@@ -934,88 +3423,81 @@ func TestMojura_AppendFilteredIDs(t *testing.T) {
 	}
 	defer testTeardown(c, t)
 
-	entries := []testStruct{
-		makeTestStruct("user_1", "contact_1", "group_1", "FOO FOO", "foo", "bar", "baz"),
-		makeTestStruct("user_1", "contact_1", "group_2", "FOO FOO", "bar"),
-		makeTestStruct("user_1", "contact_1", "group_1", "FOO FOO", "baz"),
+	foobar := makeTestStruct("user_1", "contact_1", "group_1", "foo")
+
+	var created *testStruct
+	if created, err = c.New(&foobar); err != nil {
+		t.Fatal(err)
 	}
 
-	type testcase struct {
-		tag           string
-		group         string
-		expectedCount int
+	if created.Version != 1 {
+		t.Fatalf("invalid version, expected %d and received %d", 1, created.Version)
 	}
 
-	runCases := func(cases []testcase) (err error) {
-		for _, tc := range cases {
-			var ids []string
-			filter := filters.Match("tags", tc.tag)
-			o := NewFilteringOpts(filter)
-			if ids, _, err = c.AppendFilteredIDs(ids, o); err != nil {
-				return
-			}
+	bar := makeTestStruct("user_1", "contact_1", "group_1", "bar")
+	if _, err = c.PutIfVersion(created.ID, 0, &bar); err != ErrVersionConflict {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrVersionConflict, err)
+	}
 
-			filter = filters.Match("groups", tc.group)
-			o = NewFilteringOpts(filter)
-			if ids, _, err = c.AppendFilteredIDs(ids, o); err != nil {
-				return
-			}
+	var updated *testStruct
+	if updated, err = c.PutIfVersion(created.ID, 1, &bar); err != nil {
+		t.Fatal(err)
+	}
 
-			if len(ids) != tc.expectedCount {
-				err = fmt.Errorf("invalid number of entries, expected %d and received %d for tag of <%s> and group of <%s>", tc.expectedCount, len(ids), tc.tag, tc.group)
-			}
-		}
+	if updated.Version != 2 {
+		t.Fatalf("invalid version, expected %d and received %d", 2, updated.Version)
+	}
 
+	if updated, err = c.Update(created.ID, func(ts *testStruct) (err error) {
+		ts.Value = "baz"
 		return
+	}); err != nil {
+		t.Fatal(err)
 	}
 
-	createCases := []testcase{
-		{
-			tag:           "foo",
-			group:         "group_1",
-			expectedCount: 3,
-		},
-		{
-			tag:           "bar",
-			group:         "group_1",
-			expectedCount: 4,
-		},
+	if updated.Version != 3 {
+		t.Fatalf("invalid version, expected %d and received %d", 3, updated.Version)
+	}
 
-		{
-			tag:           "baz",
-			group:         "group_1",
-			expectedCount: 4,
-		},
-		{
-			tag:           "foo",
-			group:         "group_2",
-			expectedCount: 2,
-		},
-		{
-			tag:           "bar",
-			group:         "group_2",
-			expectedCount: 3,
-		},
+	if _, err = c.PutIfVersion("00000099", 0, &bar); err != nil {
+		t.Fatal(err)
+	}
 
-		{
-			tag:           "baz",
-			group:         "group_2",
-			expectedCount: 3,
-		},
+	if _, err = c.DeleteIfVersion(created.ID, 2); err != ErrVersionConflict {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrVersionConflict, err)
 	}
 
-	for _, entry := range entries {
-		if _, err = c.New(&entry); err != nil {
-			t.Fatal(err)
-		}
+	if _, err = c.DeleteIfVersion("unknown", 1); err != ErrEntryNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryNotFound, err)
 	}
 
-	if err = runCases(createCases); err != nil {
+	if _, err = c.DeleteIfVersion(created.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Get(created.ID); err != ErrEntryNotFound {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrEntryNotFound, err)
+	}
+
+	// Entries written before versions were introduced exist with a version of 0
+	legacy := makeTestStruct("user_1", "contact_1", "group_1", "legacy")
+	if err = c.Transaction(context.Background(), func(txn *Transaction[*testStruct]) (err error) {
+		_, err = txn.replicate([]byte("00000100"), &legacy)
+		return
+	}); err != nil {
 		t.Fatal(err)
 	}
+
+	if _, err = c.PutIfVersion("00000100", 0, &bar); err != ErrVersionConflict {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrVersionConflict, err)
+	}
+
+	if _, err = c.DeleteIfVersion("00000100", 0); err != ErrVersionConflict {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrVersionConflict, err)
+	}
 }
 
-func TestMojura_Update(t *testing.T) {
+func TestMojura_PutIfVersion_replicated(t *testing.T) {
 	var (
 		c   *Mojura[*testStruct]
 		err error
@@ -1026,39 +3508,66 @@ func TestMojura_Update(t *testing.T) {
 	}
 	defer testTeardown(c, t)
 
-	foobar := makeTestStruct("user_1", "contact_1", "group_1", "FOO FOO")
-
-	var created *testStruct
-	if created, err = c.New(&foobar); err != nil {
-		t.Fatal(err)
-	}
+	var blocks testBlockWriter
+	if err = c.db.Transaction(func(btxn backend.Transaction) (err error) {
+		_, err = c.runTransaction(context.Background(), btxn, &blocks, func(txn *Transaction[*testStruct]) (err error) {
+			var created *testStruct
+			foobar := makeTestStruct("user_1", "contact_1", "group_1", "foo")
+			if created, err = txn.New(&foobar); err != nil {
+				return
+			}
 
-	foobar.ID = created.ID
-	foobar.Value = "FOO FOO"
+			_, err = txn.Update(created.ID, func(ts *testStruct) (err error) {
+				ts.Value = "bar"
+				return
+			})
+			return
+		})
 
-	var updated *testStruct
-	if updated, err = c.Update(created.ID, func(e *testStruct) (err error) {
-		*e = foobar
 		return
 	}); err != nil {
 		t.Fatal(err)
 	}
 
-	if err = testCheck(&foobar, updated); err != nil {
+	opts := MakeOpts("test_mirror", testDir)
+	opts.IsMirror = true
+
+	var mirror *Mojura[*testStruct]
+	if mirror, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
 		t.Fatal(err)
 	}
+	defer mirror.Close()
 
-	var fb *testStruct
-	if fb, err = c.Get(created.ID); err != nil {
+	// Replay the primary blocks twice to ensure replays do not bump the version
+	for i := 0; i < 2; i++ {
+		if err = mirror.importTransaction(context.Background(), func(txn *Transaction[*testStruct]) (err error) {
+			for _, b := range blocks {
+				if err = txn.processBlock(b); err != nil {
+					return
+				}
+			}
+
+			return
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var primaryVal, mirrorVal *testStruct
+	if primaryVal, err = c.Get("00000000"); err != nil {
 		t.Fatal(err)
 	}
 
-	if err = testCheck(&foobar, fb); err != nil {
+	if mirrorVal, err = mirror.Get("00000000"); err != nil {
 		t.Fatal(err)
 	}
+
+	if mirrorVal.Version != primaryVal.Version {
+		t.Fatalf("invalid mirror entry, expected %+v and received %+v", primaryVal.Entry, mirrorVal.Entry)
+	}
 }
 
-func TestMojura_ForEach(t *testing.T) {
+func TestMojura_OnImportEntries(t *testing.T) {
 	var (
 		c   *Mojura[*testStruct]
 		err error
@@ -1069,33 +3578,130 @@ func TestMojura_ForEach(t *testing.T) {
 	}
 	defer testTeardown(c, t)
 
-	foobar := makeTestStruct("user_1", "contact_1", "group_1", "FOO FOO")
+	var blocks testBlockWriter
+	if err = c.db.Transaction(func(btxn backend.Transaction) (err error) {
+		_, err = c.runTransaction(context.Background(), btxn, &blocks, func(txn *Transaction[*testStruct]) (err error) {
+			var foo, bar *testStruct
+			if foo, err = txn.New(newTestStruct("user_1", "contact_1", "group_1", "foo")); err != nil {
+				return
+			}
 
-	if _, err = c.New(&foobar); err != nil {
+			if bar, err = txn.New(newTestStruct("user_2", "contact_1", "group_1", "bar")); err != nil {
+				return
+			}
+
+			if _, err = txn.Put(foo.ID, newTestStruct("user_3", "contact_1", "group_1", "foo")); err != nil {
+				return
+			}
+
+			_, err = txn.Delete(bar.ID)
+			return
+		})
+
+		return
+	}); err != nil {
 		t.Fatal(err)
 	}
 
-	if _, err = c.New(&foobar); err != nil {
-		t.Fatal(err)
+	opts := MakeOpts("test_mirror", testDir)
+	opts.IsMirror = true
+	opts.OnImportEntries = OnImportEntries(func(ImportEvent[*Entry]) {})
+	if _, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != ErrInvalidImportEntriesHandler {
+		t.Fatalf("invalid error, expected <%v> and received <%v>", ErrInvalidImportEntriesHandler, err)
 	}
 
-	var cnt int
-	if err = c.ForEach(func(key string, v *testStruct) (err error) {
-		// We are not checking ID correctness in this test
-		foobar.ID = v.ID
+	var events []ImportEvent[*testStruct]
+	opts.OnImportEntries = OnImportEntries(func(e ImportEvent[*testStruct]) {
+		events = append(events, e)
+	})
 
-		if err = testCheck(&foobar, v); err != nil {
-			t.Fatal(err)
-		}
+	var mirror *Mojura[*testStruct]
+	if mirror, err = New[*testStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer mirror.Close()
 
-		cnt++
-		return
-	}, nil); err != nil {
+	var r *kiroku.Reader
+	if r, err = blocks.reader(); err != nil {
 		t.Fatal(err)
 	}
 
-	if cnt != 2 {
-		t.Fatalf("invalid number of entries, expected %d and received %d", 2, cnt)
+	if err = mirror.onImport(kiroku.TypeChunk, r); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("invalid number of events, expected %d and received %d", 1, len(events))
+	}
+
+	if events[0].Type != kiroku.TypeChunk {
+		t.Fatalf("invalid event type, expected <%v> and received <%v>", kiroku.TypeChunk, events[0].Type)
+	}
+
+	type testCase struct {
+		actionType  action.Type
+		entryID     string
+		value       string
+		hasPrevious bool
+		added       map[string][]string
+		removed     map[string][]string
+	}
+
+	tcs := []testCase{
+		{
+			actionType: action.TypeWrite,
+			entryID:    "00000000",
+			value:      "user_1",
+			added:      map[string][]string{"users": {"user_1"}, "contacts": {"contact_1"}, "groups": {"group_1"}},
+		},
+		{
+			actionType: action.TypeWrite,
+			entryID:    "00000001",
+			value:      "user_2",
+			added:      map[string][]string{"users": {"user_2"}, "contacts": {"contact_1"}, "groups": {"group_1"}},
+		},
+		{
+			actionType:  action.TypeWrite,
+			entryID:     "00000000",
+			value:       "user_3",
+			hasPrevious: true,
+			added:       map[string][]string{"users": {"user_3"}},
+			removed:     map[string][]string{"users": {"user_1"}},
+		},
+		{
+			actionType:  action.TypeDelete,
+			entryID:     "00000001",
+			value:       "user_2",
+			hasPrevious: true,
+			removed:     map[string][]string{"users": {"user_2"}, "contacts": {"contact_1"}, "groups": {"group_1"}},
+		},
+	}
+
+	entries := events[0].Entries
+	if len(entries) != len(tcs) {
+		t.Fatalf("invalid number of entries, expected %d and received %d", len(tcs), len(entries))
+	}
+
+	for i, tc := range tcs {
+		e := entries[i]
+		switch {
+		case e.Type != tc.actionType:
+			t.Fatalf("invalid type for entry %d, expected <%v> and received <%v>", i, tc.actionType, e.Type)
+		case e.EntryID != tc.entryID:
+			t.Fatalf("invalid entry ID for entry %d, expected <%s> and received <%s>", i, tc.entryID, e.EntryID)
+		case e.Value.UserID != tc.value:
+			t.Fatalf("invalid value for entry %d, expected <%s> and received <%s>", i, tc.value, e.Value.UserID)
+		case e.HasPrevious != tc.hasPrevious:
+			t.Fatalf("invalid has previous for entry %d, expected %v and received %v", i, tc.hasPrevious, e.HasPrevious)
+		case !reflect.DeepEqual(e.Relationships.Added, tc.added):
+			t.Fatalf("invalid added relationships for entry %d, expected %v and received %v", i, tc.added, e.Relationships.Added)
+		case !reflect.DeepEqual(e.Relationships.Removed, tc.removed):
+			t.Fatalf("invalid removed relationships for entry %d, expected %v and received %v", i, tc.removed, e.Relationships.Removed)
+		}
+	}
+
+	if entries[2].Previous.UserID != "user_1" {
+		t.Fatalf("invalid previous value, expected <%s> and received <%s>", "user_1", entries[2].Previous.UserID)
 	}
 }
 
@@ -2164,6 +4770,71 @@ func makeTestStruct(userID, contactID, groupID, value string, tags ...string) (t
 	return
 }
 
+type testBlockWriter []kiroku.Block
+
+func (t *testBlockWriter) Write(b []byte) (err error) {
+	*t = append(*t, bytes.Clone(b))
+	return
+}
+
+// reader will return a kiroku reader containing the written blocks
+func (t *testBlockWriter) reader() (r *kiroku.Reader, err error) {
+	var buf bytes.Buffer
+	w := enkodo.NewWriter(&buf)
+	for _, b := range *t {
+		if err = w.Encode(b); err != nil {
+			return
+		}
+	}
+
+	r = kiroku.NewReader(bytes.NewReader(buf.Bytes()))
+	return
+}
+
+type testTaggedStruct struct {
+	Entry
+
+	UserID string      `json:"userID" mojura:"rel=users"`
+	Group  testGroupID `json:"group" mojura:"rel=groups"`
+	Tags   []string    `json:"tags" mojura:"rel=tags"`
+	Email  string      `json:"email" mojura:"rel=emails,unique"`
+}
+
+type testSchemaV1 struct {
+	Entry
+
+	UserID    string `json:"userID" mojura:"rel=users"`
+	GroupID   string `json:"groupID" mojura:"rel=groups"`
+	ContactID string `json:"contactID"`
+}
+
+type testSchemaV2 struct {
+	Entry
+
+	UserID    string `json:"userID" mojura:"rel=users"`
+	GroupID   string `json:"groupID" mojura:"rel=teams"`
+	ContactID string `json:"contactID" mojura:"rel=contacts"`
+}
+
+type testSchemaV3 struct {
+	Entry
+
+	GroupID   string `json:"groupID" mojura:"rel=teams"`
+	ContactID string `json:"contactID" mojura:"rel=contacts"`
+}
+
+type testInvalidTaggedStruct struct {
+	Entry
+
+	Count int `json:"count" mojura:"rel=counts"`
+}
+
+type testGroupID int
+
+func (g testGroupID) String() string {
+	return fmt.Sprintf("group_%d", g)
+}
+
 type testStruct struct {
 	Entry
 
@@ -2210,3 +4881,41 @@ func (t *testStruct) compare(v *testStruct) (err error) {
 
 	return errs.Err()
 }
+
+// testLookupStruct is a testStruct which provides unique lookups
+type testLookupStruct struct {
+	testStruct
+
+	Email string `json:"email,omitempty"`
+}
+
+func (t *testLookupStruct) GetID() (id string) {
+	if t == nil {
+		return
+	}
+
+	return t.ID
+}
+
+func (t *testLookupStruct) GetLookups() (l Lookups) {
+	return Lookups{"email": t.Email}
+}
+
+// testLegacyAction is encoded the same as actions written before metadata existed
+type testLegacyAction struct {
+	Type  action.Type
+	Key   []byte
+	Value []byte
+}
+
+func (a *testLegacyAction) MarshalEnkodo(enc *enkodo.Encoder) (err error) {
+	if err = enc.Uint8(uint8(a.Type)); err != nil {
+		return
+	}
+
+	if err = enc.Bytes(a.Key); err != nil {
+		return
+	}
+
+	return enc.Bytes(a.Value)
+}
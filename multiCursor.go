@@ -120,7 +120,7 @@ func (c *multiCursor[T]) get(entryID []byte) (val T, err error) {
 	}
 
 	// Set value from bytes
-	return c.txn.m.newValueFromBytes(bs)
+	return c.txn.newValueFromBytes(entryID, bs)
 }
 
 func (c *multiCursor[T]) getCurrentRelationshipID() (relationshipID string) {
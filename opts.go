@@ -19,6 +19,10 @@ const (
 	DefaultRetryBatchFail = true
 	// DefaultIndexLength is the default index length
 	DefaultIndexLength = 8
+	// DefaultChangeBacklogSize is the default number of recent changes kept for resuming subscriptions
+	DefaultChangeBacklogSize = 1024
+	// DefaultSubscriptionBufferSize is the default channel buffer size of a subscription
+	DefaultSubscriptionBufferSize = 64
 )
 
 const (
@@ -50,14 +54,42 @@ type Opts struct {
 	MaxBatchCalls    int           `toml:"max_batch_calls"`
 	MaxBatchDuration time.Duration `toml:"max_batch_duration"`
 
+	// ChangeBacklogSize is the number of recent changes kept in memory for resuming subscriptions
+	ChangeBacklogSize int `toml:"change_backlog_size"`
+	// SubscriptionBufferSize is the channel buffer size of a subscription. Once a subscription's
+	// buffer is full, the subscription is ended
+	SubscriptionBufferSize int `toml:"subscription_buffer_size"`
+
 	RetryBatchFail              bool `toml:"retry_batch_fail"`
 	IsMirror                    bool `toml:"is_mirror"`
 	IgnoreEmptyRelationshipKeys bool `toml:"ignore_empty_relationship_keys"`
+	// IndexHistory will index the positions of the revisions of each entry, so they can be read
+	// from the history with History
+	// Note: Reading revisions requires a Source
+	// Note: Importing a snapshot clears the revisions which were indexed prior to the snapshot
+	IndexHistory bool `toml:"index_history"`
+	// SoftDelete will move deleted entries to tombstones, where they can be restored or purged
+	// Note: Mirrors must also set SoftDelete to retain the tombstones of the primary
+	SoftDelete bool `toml:"soft_delete"`
+
+	// RelationshipRenames are relationship keys which have been renamed, keyed by the
+	// previous relationship key (e.g. {"contacts": "friends"})
+	RelationshipRenames map[string]string `toml:"relationship_renames"`
+
+	// Upgraders are used to upgrade entries from older schema versions. The upgrader at index N
+	// upgrades an entry from schema version N to N+1, the current schema version is the number
+	// of upgraders. Upgraders receive and return the encoded bytes of an entry
+	Upgraders []Upgrader `toml:"-"`
+
+	// IDGenerator generates the entry IDs for new entries, defaults to a SequentialIDGenerator
+	IDGenerator IDGenerator `toml:"-"`
 
 	Initializer backend.Initializer
 	Encoder     Encoder
 
 	OnImport func(kiroku.Type, *action.Reader)
+	// OnImportEntries is called with the decoded entries of each committed import, see OnImportEntries
+	OnImportEntries ImportEntriesHandler `toml:"-"`
 
 	Source kiroku.Source
 }
@@ -88,4 +120,16 @@ func (o *Opts) fill() {
 	if o.IndexLength == 0 {
 		o.IndexLength = DefaultIndexLength
 	}
+
+	if o.ChangeBacklogSize == 0 {
+		o.ChangeBacklogSize = DefaultChangeBacklogSize
+	}
+
+	if o.SubscriptionBufferSize == 0 {
+		o.SubscriptionBufferSize = DefaultSubscriptionBufferSize
+	}
+
+	if o.IDGenerator == nil {
+		o.IDGenerator = NewSequentialIDGenerator(o.IndexLength)
+	}
 }
@@ -0,0 +1,326 @@
+package mojura
+
+import (
+	"bytes"
+
+	"github.com/mojura/mojura/filters"
+)
+
+var _ filterCursor = &orCursor[*Entry]{}
+
+func newOrCursor[T Value](txn *Transaction[T], f *filters.OrFilter) (cur *orCursor[T], err error) {
+	var c orCursor[T]
+	if len(f.Filters) == 0 {
+		err = ErrEmptyFilters
+		return
+	}
+
+	c.children = make([]filterCursor, 0, len(f.Filters))
+	for _, filter := range f.Filters {
+		var fc filterCursor
+		if fc, err = newFilterCursor(txn, filter); err != nil {
+			return
+		}
+
+		if !isEntryOrdered[T](fc) {
+			// Cursor iterates in relationship ID order, the entry IDs must be
+			// collected and sorted before they can be merged with the others
+			if fc, err = newSortedCursor(txn, fc); err != nil {
+				return
+			}
+		}
+
+		c.children = append(c.children, fc)
+	}
+
+	c.txn = txn
+	c.heads = make([][]byte, len(c.children))
+	cur = &c
+	return
+}
+
+// orCursor will merge the entries of all of it's children in entry ID order. Entries
+// which are matched by more than one child are only returned once
+type orCursor[T Value] struct {
+	txn *Transaction[T]
+
+	children []filterCursor
+	// Current entry ID for each child, nil represents an exhausted child
+	heads [][]byte
+
+	current []byte
+	reverse bool
+}
+
+func (c *orCursor[T]) getCurrentRelationshipID() (relationshipID string) {
+	// Union results are ordered by entry ID, so no relationship ID is needed to seek
+	return ""
+}
+
+func (c *orCursor[T]) setHeads(fn func(i int, child filterCursor) (entryID []byte, err error)) (err error) {
+	for i, child := range c.children {
+		var head []byte
+		head, err = fn(i, child)
+		switch err {
+		case nil:
+		case Break:
+			// Child has no more entries in the current direction
+			head = nil
+			err = nil
+
+		default:
+			return
+		}
+
+		// Keys are only valid until the child cursor moves again (within write
+		// transactions), so a copy is held for comparison
+		c.heads[i] = bytes.Clone(head)
+	}
+
+	return
+}
+
+func (c *orCursor[T]) pick() (entryID []byte, err error) {
+	for _, head := range c.heads {
+		switch {
+		case head == nil:
+		case entryID == nil:
+			entryID = head
+		case !c.reverse && bytes.Compare(head, entryID) == -1:
+			entryID = head
+		case c.reverse && bytes.Compare(head, entryID) == 1:
+			entryID = head
+		}
+	}
+
+	if entryID == nil {
+		err = Break
+		return
+	}
+
+	c.current = entryID
+	return
+}
+
+// SeekForward will seek the provided ID in a forward direction
+func (c *orCursor[T]) SeekForward(relationshipID, seekID []byte) (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	if err = c.setHeads(func(_ int, child filterCursor) ([]byte, error) {
+		return child.SeekForward(nil, seekID)
+	}); err != nil {
+		return
+	}
+
+	c.reverse = false
+	return c.pick()
+}
+
+// SeekReverse will seek the provided ID in a reverse direction
+func (c *orCursor[T]) SeekReverse(relationshipID, seekID []byte) (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	if err = c.setHeads(func(_ int, child filterCursor) ([]byte, error) {
+		return seekAtOrBefore(child, seekID)
+	}); err != nil {
+		return
+	}
+
+	c.reverse = true
+	return c.pick()
+}
+
+// First will return the first entry
+func (c *orCursor[T]) First() (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	if err = c.setHeads(func(_ int, child filterCursor) ([]byte, error) {
+		return child.First()
+	}); err != nil {
+		return
+	}
+
+	c.reverse = false
+	return c.pick()
+}
+
+// Last will return the last entry
+func (c *orCursor[T]) Last() (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	if err = c.setHeads(func(_ int, child filterCursor) ([]byte, error) {
+		return child.Last()
+	}); err != nil {
+		return
+	}
+
+	c.reverse = true
+	return c.pick()
+}
+
+// Next will return the next entry
+func (c *orCursor[T]) Next() (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	if c.current == nil {
+		err = Break
+		return
+	}
+
+	if c.reverse {
+		// Direction has changed, move all of the children past the current entry
+		err = c.setHeads(func(_ int, child filterCursor) ([]byte, error) {
+			return seekAfter(child, c.current)
+		})
+	} else {
+		// Only move the children which are positioned on the current entry
+		err = c.setHeads(func(i int, child filterCursor) ([]byte, error) {
+			if !bytes.Equal(c.heads[i], c.current) {
+				return c.heads[i], nil
+			}
+
+			return child.Next()
+		})
+	}
+
+	if err != nil {
+		return
+	}
+
+	c.reverse = false
+	return c.pick()
+}
+
+// Prev will return the previous entry
+func (c *orCursor[T]) Prev() (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	if c.current == nil {
+		err = Break
+		return
+	}
+
+	if !c.reverse {
+		// Direction has changed, move all of the children before the current entry
+		err = c.setHeads(func(_ int, child filterCursor) ([]byte, error) {
+			return seekBefore(child, c.current)
+		})
+	} else {
+		// Only move the children which are positioned on the current entry
+		err = c.setHeads(func(i int, child filterCursor) ([]byte, error) {
+			if !bytes.Equal(c.heads[i], c.current) {
+				return c.heads[i], nil
+			}
+
+			return child.Prev()
+		})
+	}
+
+	if err != nil {
+		return
+	}
+
+	c.reverse = true
+	return c.pick()
+}
+
+// HasForward will determine if an entry exists in a forward direction
+func (c *orCursor[T]) HasForward(entryID []byte) (ok bool, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	for _, child := range c.children {
+		if ok, err = child.HasForward(entryID); ok || err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// HasReverse will determine if an entry exists in a reverse direction
+func (c *orCursor[T]) HasReverse(entryID []byte) (ok bool, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	for _, child := range c.children {
+		if ok, err = child.HasReverse(entryID); ok || err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (c *orCursor[T]) teardown() {
+	c.txn = nil
+	c.children = nil
+	c.heads = nil
+}
+
+// isEntryOrdered will determine if a filter cursor iterates in entry ID order
+func isEntryOrdered[T Value](fc filterCursor) (ok bool) {
+	switch n := fc.(type) {
+	case *nopCursor, *matchCursor[T], *baseComparisonCursor[T], *orCursor[T], *sortedCursor[T]:
+		return true
+	case *andCursor[T]:
+		// Intersections are returned in the order of their primary cursor
+		return isEntryOrdered[T](n.primary)
+
+	default:
+		return false
+	}
+}
+
+func seekAfter(fc filterCursor, entryID []byte) (next []byte, err error) {
+	if next, err = fc.SeekForward(nil, entryID); err != nil {
+		return
+	}
+
+	if !bytes.Equal(next, entryID) {
+		return
+	}
+
+	return fc.Next()
+}
+
+func seekAtOrBefore(fc filterCursor, entryID []byte) (prev []byte, err error) {
+	prev, err = fc.SeekForward(nil, entryID)
+	switch {
+	case err == Break:
+		// No entries exist at or after the entry ID, the last entry will precede it
+		return fc.Last()
+	case err != nil:
+		return
+	case bytes.Equal(prev, entryID):
+		return
+	}
+
+	return fc.Prev()
+}
+
+func seekBefore(fc filterCursor, entryID []byte) (prev []byte, err error) {
+	if prev, err = seekAtOrBefore(fc, entryID); err != nil {
+		return
+	}
+
+	if !bytes.Equal(prev, entryID) {
+		return
+	}
+
+	return fc.Prev()
+}
@@ -0,0 +1,87 @@
+package mojura
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/mojura/mojura/filters"
+)
+
+func Test_orCursor_direction_change(t *testing.T) {
+	var (
+		m   *Mojura[*testStruct]
+		err error
+	)
+
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+
+	if m, err = New[*testStruct](MakeOpts("test_or_cursor", testDir), "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	defer testTeardown(m, t)
+
+	entries := []*testStruct{
+		newTestStruct("user_1", "contact_1", "group_1", "0"),
+		newTestStruct("user_2", "contact_1", "group_1", "1"),
+		newTestStruct("user_3", "contact_1", "group_1", "2"),
+		newTestStruct("user_1", "contact_1", "group_1", "3"),
+		newTestStruct("user_2", "contact_1", "group_1", "4"),
+	}
+
+	type step struct {
+		fn       func(filterCursor) ([]byte, error)
+		expected string
+	}
+
+	first := func(fc filterCursor) ([]byte, error) { return fc.First() }
+	last := func(fc filterCursor) ([]byte, error) { return fc.Last() }
+	next := func(fc filterCursor) ([]byte, error) { return fc.Next() }
+	prev := func(fc filterCursor) ([]byte, error) { return fc.Prev() }
+
+	steps := []step{
+		{fn: first, expected: "00000000"},
+		{fn: next, expected: "00000001"},
+		{fn: next, expected: "00000003"},
+		{fn: prev, expected: "00000001"},
+		{fn: prev, expected: "00000000"},
+		{fn: next, expected: "00000001"},
+		{fn: last, expected: "00000004"},
+		{fn: prev, expected: "00000003"},
+		{fn: next, expected: "00000004"},
+	}
+
+	if err = m.Transaction(context.Background(), func(txn *Transaction[*testStruct]) (err error) {
+		for _, entry := range entries {
+			if _, err = txn.New(entry); err != nil {
+				return
+			}
+		}
+
+		var cur filterCursor
+		f := filters.Or(filters.Match("users", "user_1"), filters.Match("users", "user_2"))
+		if cur, err = newOrCursor(txn, f); err != nil {
+			return
+		}
+
+		for i, s := range steps {
+			var entryID []byte
+			if entryID, err = s.fn(cur); err != nil {
+				err = fmt.Errorf("error iterating (step #%d): %v", i, err)
+				return
+			}
+
+			if id := string(entryID); id != s.expected {
+				err = fmt.Errorf("invalid ID, expected <%s> and received <%s> (step #%d)", s.expected, id, i)
+				return
+			}
+		}
+
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
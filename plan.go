@@ -0,0 +1,288 @@
+package mojura
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/mojura/backend"
+	"github.com/mojura/mojura/filters"
+)
+
+// Plan represents the execution plan for a set of filters
+type Plan struct {
+	// Filters are listed in the order they will be executed. The first filter
+	// drives iteration while the remaining filters are checked for each entry
+	Filters []PlannedFilter `json:"filters"`
+	// Reordered notes if the filters were reordered from the provided order
+	Reordered bool `json:"reordered"`
+}
+
+func (p *Plan) getFilters() (fs []Filter) {
+	fs = make([]Filter, 0, len(p.Filters))
+	for _, pf := range p.Filters {
+		fs = append(fs, pf.Filter)
+	}
+
+	return
+}
+
+// PlannedFilter represents a filter within a plan
+type PlannedFilter struct {
+	Filter Filter `json:"filter"`
+	// Index of the filter within the provided filters
+	Index int `json:"index"`
+	// Estimate is the estimated number of matching entries. Filters which could not
+	// drive iteration are not estimated and will have a value of -1
+	// Note: Estimates stop counting once they exceed the smallest estimate found, or
+	// maxEstimate when no estimate has been found yet
+	Estimate int64 `json:"estimate"`
+}
+
+// maxEstimate caps the number of keys scanned while estimating a filter
+const maxEstimate = 1024
+
+// plan will move the most selective filter to the front of the filters. Only filters
+// which return entries in entry ID order are candidates, as a filter which iterates
+// in relationship ID order determines the order of the results and the LastID format
+func (t *Transaction[T]) plan(fs []Filter) (p Plan, err error) {
+	p.Filters = make([]PlannedFilter, 0, len(fs))
+	for i, f := range fs {
+		p.Filters = append(p.Filters, PlannedFilter{Filter: f, Index: i, Estimate: -1})
+	}
+
+	if len(fs) < 2 || !isOrderedFilter(fs[0]) {
+		// The leading filter is pinned as primary
+		return
+	}
+
+	best := -1
+	limit := int64(maxEstimate)
+	// Match filters are estimated first, as their counts are maintained on write. This
+	// caps the scans of the remaining filters at the smallest match count
+	for _, counted := range []bool{true, false} {
+		for i, f := range fs {
+			if !isOrderedFilter(f) || isCountedFilter(f) != counted {
+				continue
+			}
+
+			var n int64
+			if n, err = t.estimate(f, limit); err != nil {
+				return
+			}
+
+			p.Filters[i].Estimate = n
+			if best == -1 || n < limit {
+				best = i
+				limit = n
+			}
+		}
+	}
+
+	if best <= 0 {
+		return
+	}
+
+	primary := p.Filters[best]
+	copy(p.Filters[1:best+1], p.Filters[:best])
+	p.Filters[0] = primary
+	p.Reordered = true
+	return
+}
+
+func (t *Transaction[T]) explain(o *FilteringOpts) (p Plan, err error) {
+	if o == nil {
+		o = defaultFilteringOpts
+	}
+
+	return t.plan(o.Filters)
+}
+
+// estimate will return the estimated number of entries which match a filter. If the
+// limit is not negative, counting will stop once the limit has been reached
+func (t *Transaction[T]) estimate(f Filter, limit int64) (n int64, err error) {
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	switch filter := f.(type) {
+	case *filters.MatchFilter:
+		return t.estimateMatch(filter, limit)
+	case *filters.InverseMatchFilter:
+		return t.estimateInverseMatch(filter, limit)
+	case *filters.ComparisonFilter:
+		return t.estimateComparison(filter, limit)
+	case *filters.OrFilter:
+		return t.estimateOr(filter, limit)
+	case *filters.AndFilter:
+		return t.estimateAnd(filter, limit)
+
+	default:
+		err = fmt.Errorf("filter of %T is not supported", filter)
+		return
+	}
+}
+
+func (t *Transaction[T]) estimateMatch(f *filters.MatchFilter, limit int64) (n int64, err error) {
+	// Relationship counts are maintained on write, so the exact count is available
+	return t.getRelationshipCount([]byte(f.RelationshipKey), []byte(f.RelationshipID))
+}
+
+func (t *Transaction[T]) estimateInverseMatch(f *filters.InverseMatchFilter, limit int64) (n int64, err error) {
+	var parentBkt backend.Bucket
+	if parentBkt, err = t.getRelationshipBucket([]byte(f.RelationshipKey)); err != nil {
+		return
+	}
+
+	target := []byte(f.RelationshipID)
+	n = countRelationshipKeys(parentBkt, nil, nil, limit, func(relationshipID []byte) bool {
+		return !bytes.Equal(relationshipID, target)
+	})
+	return
+}
+
+func (t *Transaction[T]) estimateComparison(f *filters.ComparisonFilter, limit int64) (n int64, err error) {
+	rangeStart := []byte(f.RangeStart)
+	rangeEnd := []byte(f.RangeEnd)
+	if len(f.RelationshipKey) == 0 {
+		var bkt backend.Bucket
+		if bkt, err = t.getEntriesBucket(); err != nil {
+			return
+		}
+
+		n = countKeys(bkt.Cursor(), rangeStart, rangeEnd, limit)
+		return
+	}
+
+	var parentBkt backend.Bucket
+	if parentBkt, err = t.getRelationshipBucket([]byte(f.RelationshipKey)); err != nil {
+		return
+	}
+
+	n = countRelationshipKeys(parentBkt, rangeStart, rangeEnd, limit, nil)
+	return
+}
+
+func (t *Transaction[T]) estimateOr(f *filters.OrFilter, limit int64) (n int64, err error) {
+	for _, child := range f.Filters {
+		childLimit := limit
+		if limit >= 0 {
+			childLimit = limit - n
+		}
+
+		var count int64
+		if count, err = t.estimate(child, childLimit); err != nil {
+			return
+		}
+
+		// Overlapping children will be counted more than once, so this is an upper bound
+		if n += count; limit >= 0 && n >= limit {
+			return
+		}
+	}
+
+	return
+}
+
+func (t *Transaction[T]) estimateAnd(f *filters.AndFilter, limit int64) (n int64, err error) {
+	n = -1
+	for _, child := range f.Filters {
+		var count int64
+		if count, err = t.estimate(child, limit); err != nil {
+			return
+		}
+
+		// The intersection can be no larger than it's smallest child
+		if n == -1 || count < n {
+			n = count
+			limit = count
+		}
+	}
+
+	if n == -1 {
+		n = 0
+	}
+
+	return
+}
+
+// isCountedFilter will determine if a filter can be estimated without scanning keys
+func isCountedFilter(f Filter) (ok bool) {
+	_, ok = f.(*filters.MatchFilter)
+	return
+}
+
+// isOrderedFilter will determine if a filter returns entries in entry ID order
+func isOrderedFilter(f Filter) (ok bool) {
+	switch n := f.(type) {
+	case *filters.MatchFilter, *filters.OrFilter:
+		return true
+	case *filters.ComparisonFilter:
+		return len(n.RelationshipKey) == 0
+	case *filters.AndFilter:
+		return len(n.Filters) > 0 && isOrderedFilter(n.Filters[0])
+
+	default:
+		return false
+	}
+}
+
+func countKeys(cur backend.Cursor, rangeStart, rangeEnd []byte, limit int64) (n int64) {
+	if limit == 0 {
+		return
+	}
+
+	var key []byte
+	if len(rangeStart) > 0 {
+		key, _ = cur.Seek(rangeStart)
+	} else {
+		key, _ = cur.First()
+	}
+
+	for ; key != nil; key, _ = cur.Next() {
+		if len(rangeEnd) > 0 && bytes.Compare(key, rangeEnd) == 1 {
+			return
+		}
+
+		if n++; limit >= 0 && n >= limit {
+			return
+		}
+	}
+
+	return
+}
+
+func countRelationshipKeys(parentBkt backend.Bucket, rangeStart, rangeEnd []byte, limit int64, include func(relationshipID []byte) bool) (n int64) {
+	cur := parentBkt.Cursor()
+	var relationshipID []byte
+	if len(rangeStart) > 0 {
+		relationshipID, _ = cur.Seek(rangeStart)
+	} else {
+		relationshipID, _ = cur.First()
+	}
+
+	for ; relationshipID != nil; relationshipID, _ = cur.Next() {
+		if len(rangeEnd) > 0 && bytes.Compare(relationshipID, rangeEnd) == 1 {
+			return
+		}
+
+		if include != nil && !include(relationshipID) {
+			continue
+		}
+
+		bkt := parentBkt.GetBucket(relationshipID)
+		if bkt == nil {
+			continue
+		}
+
+		bktLimit := limit
+		if limit >= 0 {
+			bktLimit = limit - n
+		}
+
+		if n += countKeys(bkt.Cursor(), nil, nil, bktLimit); limit >= 0 && n >= limit {
+			return
+		}
+	}
+
+	return
+}
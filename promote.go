@@ -0,0 +1,27 @@
+package mojura
+
+import "github.com/mojura/backend"
+
+// restoreIndex will ensure the current index is ahead of the last entry ID and that the
+// index length of a rekey is in use. Mirrors only learn the index through the imported
+// entry IDs, so the index is restored before promotion
+func (t *Transaction[T]) restoreIndex() (err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getEntriesBucket(); err != nil {
+		return
+	}
+
+	entryID, _ := bkt.Cursor().Last()
+	if entryID == nil {
+		t.applyIndexLength()
+		return
+	}
+
+	t.trackIndexLength(entryID)
+	t.applyIndexLength()
+	if index, ok := parseIndex(t.getIDGenerator(), entryID); ok && index >= t.meta.CurrentIndex {
+		t.setIndex(index + 1)
+	}
+
+	return
+}
@@ -1,5 +1,10 @@
 package mojura
 
+import (
+	"context"
+	"iter"
+)
+
 func MakeReadWrapper[T Value](m *Mojura[T]) (r ReadWrapper[T]) {
 	r.m = m
 	return
@@ -14,49 +19,140 @@ func (r *ReadWrapper[T]) Exists(entryID string) (exists bool, err error) {
 	return r.m.Exists(entryID)
 }
 
+// ExistsCtx will notiy if an entry exists for a given entry ID
+func (r *ReadWrapper[T]) ExistsCtx(ctx context.Context, entryID string) (exists bool, err error) {
+	return r.m.ExistsCtx(ctx, entryID)
+}
+
 // Get will attempt to get an entry by ID
 func (r *ReadWrapper[T]) Get(entryID string) (val T, err error) {
 	return r.m.Get(entryID)
 }
 
+// GetCtx will attempt to get an entry by ID
+func (r *ReadWrapper[T]) GetCtx(ctx context.Context, entryID string) (val T, err error) {
+	return r.m.GetCtx(ctx, entryID)
+}
+
+// GetByLookup will attempt to get an entry by lookup
+// Note: Will return ErrLookupNotFound if the lookup does not exist
+func (r *ReadWrapper[T]) GetByLookup(lookupKey, lookupID string) (val T, err error) {
+	return r.m.GetByLookup(lookupKey, lookupID)
+}
+
+// GetByLookupCtx will attempt to get an entry by lookup
+// Note: Will return ErrLookupNotFound if the lookup does not exist
+func (r *ReadWrapper[T]) GetByLookupCtx(ctx context.Context, lookupKey, lookupID string) (val T, err error) {
+	return r.m.GetByLookupCtx(ctx, lookupKey, lookupID)
+}
+
 // GetFiltered will attempt to get the filtered entries
 func (r *ReadWrapper[T]) GetFiltered(o *FilteringOpts) (filtered []T, lastID string, err error) {
 	return r.m.GetFiltered(o)
 }
 
+// GetFilteredCtx will attempt to get the filtered entries
+func (r *ReadWrapper[T]) GetFilteredCtx(ctx context.Context, o *FilteringOpts) (filtered []T, lastID string, err error) {
+	return r.m.GetFilteredCtx(ctx, o)
+}
+
 // GetFilteredIDs will attempt to get the filtered entry IDs
 func (r *ReadWrapper[T]) GetFilteredIDs(o *FilteringOpts) (filtered []string, lastID string, err error) {
 	return r.m.GetFilteredIDs(o)
 }
 
+// GetFilteredIDsCtx will attempt to get the filtered entry IDs
+func (r *ReadWrapper[T]) GetFilteredIDsCtx(ctx context.Context, o *FilteringOpts) (filtered []string, lastID string, err error) {
+	return r.m.GetFilteredIDsCtx(ctx, o)
+}
+
 // AppendFiltered will attempt to append all entries associated with a set of given filters
 func (r *ReadWrapper[T]) AppendFiltered(in []T, o *FilteringOpts) (filtered []T, lastID string, err error) {
 	return r.m.AppendFiltered(in, o)
 }
 
+// AppendFilteredCtx will attempt to append all entries associated with a set of given filters
+func (r *ReadWrapper[T]) AppendFilteredCtx(ctx context.Context, in []T, o *FilteringOpts) (filtered []T, lastID string, err error) {
+	return r.m.AppendFilteredCtx(ctx, in, o)
+}
+
 // AppendFilteredIDs will attempt to append all entry IDs associated with a set of given filters
 func (r *ReadWrapper[T]) AppendFilteredIDs(in []string, o *FilteringOpts) (filtered []string, lastID string, err error) {
 	return r.m.AppendFilteredIDs(in, o)
 }
 
+// AppendFilteredIDsCtx will attempt to append all entry IDs associated with a set of given filters
+func (r *ReadWrapper[T]) AppendFilteredIDsCtx(ctx context.Context, in []string, o *FilteringOpts) (filtered []string, lastID string, err error) {
+	return r.m.AppendFilteredIDsCtx(ctx, in, o)
+}
+
 // GetFirst will attempt to get the first entry which matches the provided filters
 // Note: Will return ErrEntryNotFound if no match is found
 func (r *ReadWrapper[T]) GetFirst(o *FilteringOpts) (val T, err error) {
 	return r.m.GetFirst(o)
 }
 
+// GetFirstCtx will attempt to get the first entry which matches the provided filters
+// Note: Will return ErrEntryNotFound if no match is found
+func (r *ReadWrapper[T]) GetFirstCtx(ctx context.Context, o *FilteringOpts) (val T, err error) {
+	return r.m.GetFirstCtx(ctx, o)
+}
+
 // GetLast will attempt to get the last entry which matches the provided filters
 // Note: Will return ErrEntryNotFound if no match is found
 func (r *ReadWrapper[T]) GetLast(o *FilteringOpts) (val T, err error) {
 	return r.m.GetLast(o)
 }
 
+// GetLastCtx will attempt to get the last entry which matches the provided filters
+// Note: Will return ErrEntryNotFound if no match is found
+func (r *ReadWrapper[T]) GetLastCtx(ctx context.Context, o *FilteringOpts) (val T, err error) {
+	return r.m.GetLastCtx(ctx, o)
+}
+
+// Count will return the number of entries which match the provided filtering options
+// Note: Limit is ignored
+func (r *ReadWrapper[T]) Count(o *FilteringOpts) (n int64, err error) {
+	return r.m.Count(o)
+}
+
+// CountCtx will return the number of entries which match the provided filtering options
+// Note: Limit is ignored
+func (r *ReadWrapper[T]) CountCtx(ctx context.Context, o *FilteringOpts) (n int64, err error) {
+	return r.m.CountCtx(ctx, o)
+}
+
 // ForEach will iterate through each of the entries
 func (r *ReadWrapper[T]) ForEach(fn ForEachFn[T], o *FilteringOpts) (err error) {
 	return r.m.ForEach(fn, o)
 }
 
+// ForEachCtx will iterate through each of the entries
+func (r *ReadWrapper[T]) ForEachCtx(ctx context.Context, fn ForEachFn[T], o *FilteringOpts) (err error) {
+	return r.m.ForEachCtx(ctx, fn, o)
+}
+
 // ForEachID will iterate through each of the entry IDs
 func (r *ReadWrapper[T]) ForEachID(fn ForEachIDFn, o *FilteringOpts) (err error) {
 	return r.m.ForEachID(fn, o)
 }
+
+// ForEachIDCtx will iterate through each of the entry IDs
+func (r *ReadWrapper[T]) ForEachIDCtx(ctx context.Context, fn ForEachIDFn, o *FilteringOpts) (err error) {
+	return r.m.ForEachIDCtx(ctx, fn, o)
+}
+
+// All will return an iterator of the entries which match the filtering options
+func (r *ReadWrapper[T]) All(ctx context.Context, o *FilteringOpts, errp *error) iter.Seq2[string, T] {
+	return r.m.All(ctx, o, errp)
+}
+
+// IDs will return an iterator of the entry IDs which match the filtering options
+func (r *ReadWrapper[T]) IDs(ctx context.Context, o *FilteringOpts, errp *error) iter.Seq[string] {
+	return r.m.IDs(ctx, o, errp)
+}
+
+// History will return the revisions of an entry, oldest first
+func (r *ReadWrapper[T]) History(ctx context.Context, entryID string) (revisions []Revision[T], err error) {
+	return r.m.History(ctx, entryID)
+}
@@ -1,6 +1,11 @@
 package mojura
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
 
 func makeType[T any]() func() T {
 	var ref T
@@ -41,3 +46,182 @@ func getTypes(typ reflect.Type, kind reflect.Kind) (types []reflect.Type) {
 
 	return
 }
+
+const (
+	relationshipTag       = "mojura"
+	relationshipTagPrefix = "rel="
+)
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// relationshipField is a struct field which has been tagged as a relationship
+// (e.g. `mojura:"rel=users"` or `mojura:"rel=emails,unique"`)
+type relationshipField struct {
+	// Relationship key, including the unique suffix when set
+	key  string
+	name string
+
+	index []int
+	kind  relationshipFieldKind
+}
+
+func (r *relationshipField) get(val reflect.Value) (relationship Relationship) {
+	field, err := val.FieldByIndexErr(r.index)
+	if err != nil {
+		// Embedded pointer is nil
+		return
+	}
+
+	switch r.kind {
+	case relationshipFieldString:
+		return Relationship{field.String()}
+	case relationshipFieldStrings:
+		for i := 0; i < field.Len(); i++ {
+			relationship = append(relationship, field.Index(i).String())
+		}
+
+		return
+	case relationshipFieldStringer:
+		if (field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface) && field.IsNil() {
+			return Relationship{""}
+		}
+
+		return Relationship{field.Interface().(fmt.Stringer).String()}
+	case relationshipFieldPtrStringer:
+		return Relationship{field.Addr().Interface().(fmt.Stringer).String()}
+	}
+
+	return
+}
+
+type relationshipFieldKind uint8
+
+const (
+	relationshipFieldString relationshipFieldKind = iota
+	relationshipFieldStrings
+	relationshipFieldStringer
+	relationshipFieldPtrStringer
+)
+
+func getRelationshipFieldKind(typ reflect.Type) (kind relationshipFieldKind, ok bool) {
+	switch {
+	case typ.Implements(stringerType):
+		return relationshipFieldStringer, true
+	case reflect.PointerTo(typ).Implements(stringerType):
+		return relationshipFieldPtrStringer, true
+	case typ.Kind() == reflect.String:
+		return relationshipFieldString, true
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.String:
+		return relationshipFieldStrings, true
+
+	default:
+		return
+	}
+}
+
+// getRelationshipFields will return the tagged relationship fields of a type. Embedded
+// structs are walked, so relationships can be declared by a shared base type
+func getRelationshipFields(typ reflect.Type) (fields []relationshipField, err error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+
+	return appendRelationshipFields(nil, typ, nil)
+}
+
+func appendRelationshipFields(in []relationshipField, typ reflect.Type, parent []int) (fields []relationshipField, err error) {
+	fields = in
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		index := append(slices.Clone(parent), i)
+		tag, ok := field.Tag.Lookup(relationshipTag)
+		if !ok {
+			if !field.Anonymous {
+				continue
+			}
+
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() != reflect.Struct {
+				continue
+			}
+
+			if fields, err = appendRelationshipFields(fields, embedded, index); err != nil {
+				return
+			}
+
+			continue
+		}
+
+		key, ok := strings.CutPrefix(tag, relationshipTagPrefix)
+		if !ok || len(key) == 0 || strings.HasPrefix(key, ",") {
+			err = fmt.Errorf("invalid relationship tag <%s> for field <%s>, expected format of rel=<relationship key>", tag, field.Name)
+			return
+		}
+
+		var r relationshipField
+		r.key = key
+		r.name = field.Name
+		r.index = index
+		if r.kind, ok = getRelationshipFieldKind(field.Type); !ok {
+			err = fmt.Errorf("invalid relationship field <%s> of type %v, expected string, []string or fmt.Stringer", field.Name, field.Type)
+			return
+		}
+
+		fields = append(fields, r)
+	}
+
+	return
+}
+
+// getTaggedRelationships will get the relationships of a value from it's tagged fields
+func getTaggedRelationships(fields []relationshipField, v any) (r Relationships) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return
+		}
+
+		val = val.Elem()
+	}
+
+	r = make(Relationships, 0, len(fields))
+	for i := range fields {
+		r = append(r, fields[i].get(val))
+	}
+
+	return
+}
+
+// validateRelationshipFields will ensure the provided relationships match the tagged fields
+func validateRelationshipFields(fields []relationshipField, relationships []string) (err error) {
+	if len(fields) != len(relationships) {
+		err = fmt.Errorf("%v: %d relationships were provided and %d relationship fields are tagged", ErrInvalidNumberOfRelationships, len(relationships), len(fields))
+		return
+	}
+
+	for i, field := range fields {
+		if field.key != relationships[i] {
+			err = fmt.Errorf("relationship mismatch at index %d, field <%s> is tagged as <%s> and <%s> was provided", i, field.name, field.key, relationships[i])
+			return
+		}
+	}
+
+	return
+}
+
+func getRelationshipKeys(fields []relationshipField) (relationships []string) {
+	relationships = make([]string, 0, len(fields))
+	for _, field := range fields {
+		relationships = append(relationships, field.key)
+	}
+
+	return
+}
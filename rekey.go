@@ -0,0 +1,291 @@
+package mojura
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mojura/backend"
+	"github.com/mojura/mojura/action"
+)
+
+// initIndexLength will apply the index length of a rekeyed database and warn when the
+// index is nearing the index length
+func (m *Mojura[T]) initIndexLength() (err error) {
+	g, ok := m.getIDGenerator().(*SequentialIDGenerator)
+	if !ok {
+		return
+	}
+
+	var next *SequentialIDGenerator
+	if err = m.ReadTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+		if err = txn.ensureMeta(); err != nil {
+			return
+		}
+
+		if txn.applyIndexLength() {
+			m.out.Notificationf("Database has been rekeyed, using index length of %d in place of %d", txn.meta.IndexLength, g.IndexLength())
+			g = txn.nextIDGenerator
+			next = g
+		}
+
+		if g.isNearCapacity(txn.meta.CurrentIndex) {
+			m.out.Warningf("Index of %d is nearing the index length of %d, use Rekey to widen the index length", txn.meta.CurrentIndex, g.IndexLength())
+		}
+
+		return
+	}); err != nil {
+		return
+	}
+
+	m.switchIDGenerator(next)
+	return
+}
+
+func (m *Mojura[T]) getIDGenerator() (g IDGenerator) {
+	m.idMux.RLock()
+	defer m.idMux.RUnlock()
+	return m.idGenerator
+}
+
+// switchIDGenerator will switch to the ID generator of a rekeyed index length, this is called
+// once the transaction which applied the index length has been committed
+func (m *Mojura[T]) switchIDGenerator(next *SequentialIDGenerator) {
+	if next == nil {
+		return
+	}
+
+	m.idMux.Lock()
+	defer m.idMux.Unlock()
+	m.idGenerator = next
+}
+
+// getIDGenerator will return the ID generator of the transaction, which is the generator of a
+// rekeyed index length once one has been applied within the transaction
+func (t *Transaction[T]) getIDGenerator() (g IDGenerator) {
+	if t.nextIDGenerator != nil {
+		return t.nextIDGenerator
+	}
+
+	return t.m.getIDGenerator()
+}
+
+// applyIndexLength will apply a sequential ID generator of the rekeyed index length to the
+// transaction, returning whether or not the generator was applied. The generator is switched
+// to once the transaction has been committed
+func (t *Transaction[T]) applyIndexLength() (ok bool) {
+	var g *SequentialIDGenerator
+	if g, ok = t.getIDGenerator().(*SequentialIDGenerator); !ok {
+		return
+	}
+
+	if ok = t.meta.IndexLength > g.IndexLength(); !ok {
+		return
+	}
+
+	t.nextIDGenerator = NewSequentialIDGenerator(t.meta.IndexLength)
+	return
+}
+
+// trackIndexLength will widen the index length of the meta when an imported entry ID is
+// wider than the known index length, which is how mirrors learn of a rekey
+func (t *Transaction[T]) trackIndexLength(entryID []byte) {
+	g, ok := t.getIDGenerator().(*SequentialIDGenerator)
+	if !ok {
+		return
+	}
+
+	if _, ok = g.ParseIndex(string(entryID)); !ok {
+		return
+	}
+
+	if len(entryID) <= max(g.IndexLength(), t.meta.IndexLength) {
+		return
+	}
+
+	t.meta.IndexLength = len(entryID)
+	t.metaUpdated = true
+}
+
+// rekey will move all sequential entries and tombstones to the entry IDs of the next
+// generator. The next generator is applied to the transaction, so entries created after the
+// rekey use the next generator
+func (t *Transaction[T]) rekey(current, next *SequentialIDGenerator) (n int64, err error) {
+	var entryIDs []string
+	if err = t.ForEachID(func(entryID string) (err error) {
+		if _, ok := current.ParseIndex(entryID); ok {
+			entryIDs = append(entryIDs, entryID)
+		}
+
+		return
+	}, nil); err != nil {
+		return
+	}
+
+	var tombstoneIDs []string
+	if t.m.opts.SoftDelete {
+		if tombstoneIDs, err = t.getSequentialTombstoneIDs(current); err != nil {
+			return
+		}
+	}
+
+	for i, entryID := range append(entryIDs, tombstoneIDs...) {
+		index, _ := current.ParseIndex(entryID)
+
+		var newEntryID string
+		if newEntryID, err = next.NewID(index); err != nil {
+			return
+		}
+
+		if newEntryID == entryID {
+			continue
+		}
+
+		if _, err = t.move([]byte(entryID), []byte(newEntryID)); err != nil {
+			err = fmt.Errorf("error moving <%s> to <%s>: %v", entryID, newEntryID, err)
+			return
+		}
+
+		if i < len(entryIDs) {
+			n++
+		}
+	}
+
+	t.meta.IndexLength = next.IndexLength()
+	t.metaUpdated = true
+	t.applyIndexLength()
+	return
+}
+
+// getSequentialTombstoneIDs will return the entry IDs of tombstones which were created by
+// the provided generator
+func (t *Transaction[T]) getSequentialTombstoneIDs(g *SequentialIDGenerator) (entryIDs []string, err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getTombstonesBucket(); err != nil {
+		return
+	}
+
+	err = bkt.ForEach(func(entryID, _ []byte) (err error) {
+		if _, ok := g.ParseIndex(string(entryID)); ok {
+			entryIDs = append(entryIDs, string(entryID))
+		}
+
+		return
+	})
+
+	return
+}
+
+// move will move an entry, or the tombstone of a deleted entry, to a new entry ID. The
+// relationships, lookups, schema version and history of the entry are carried over, and a
+// single move block is written so mirrors are able to apply the same move
+func (t *Transaction[T]) move(entryID, newEntryID []byte) (moved T, err error) {
+	var exists bool
+	if exists, err = t.exists(newEntryID); err != nil {
+		return
+	}
+
+	if exists {
+		err = ErrEntryExists
+		return
+	}
+
+	if exists, err = t.exists(entryID); err != nil {
+		return
+	}
+
+	var (
+		bs       []byte
+		position uint64
+	)
+
+	if exists {
+		if moved, bs, err = t.moveEntry(entryID, newEntryID); err != nil {
+			return
+		}
+
+		// The delete of the previous entry ID contains the moved value, so subscriptions
+		// are able to follow the move
+		position = t.addChange(action.TypeDelete, entryID, bs, nil)
+		t.addChange(action.TypeWrite, newEntryID, bs, nil)
+	} else if err = t.moveTombstone(entryID, newEntryID); err != nil {
+		return
+	}
+
+	if err = t.moveHistory(entryID, newEntryID); err != nil {
+		return
+	}
+
+	t.trackIndexLength(newEntryID)
+	aw := t.newChangeWriter(t.newChangeMetadata(time.Now(), position))
+	err = aw.Move(entryID, action.Move{EntryID: string(newEntryID), Value: bs})
+	return
+}
+
+func (t *Transaction[T]) moveEntry(entryID, newEntryID []byte) (moved T, bs []byte, err error) {
+	if moved, err = t.get(entryID); err != nil {
+		return
+	}
+
+	var indexed T
+	if indexed, err = t.getIndexed(entryID, moved); err != nil {
+		return
+	}
+
+	var ls []action.Lookup
+	if ls, err = t.getManualLookups(entryID); err != nil {
+		return
+	}
+
+	if err = t.unsetManualLookups(entryID); err != nil {
+		return
+	}
+
+	if err = t.unsetLookups(getLookups(indexed), entryID); err != nil {
+		return
+	}
+
+	if err = t.unsetRelationships(t.m.getRelationships(indexed), entryID); err != nil {
+		return
+	}
+
+	if err = t.deleteEntry(entryID); err != nil {
+		return
+	}
+
+	// Timestamps and version are retained, as the entry itself has not changed
+	moved.SetID(string(newEntryID))
+	if bs, err = t.m.marshal(moved); err != nil {
+		return
+	}
+
+	var bkt backend.Bucket
+	if bkt, err = t.getEntriesBucket(); err != nil {
+		return
+	}
+
+	if err = bkt.Put(newEntryID, bs); err != nil {
+		return
+	}
+
+	t.addEntryCount(1)
+	if err = t.setSchemaVersion(newEntryID); err != nil {
+		return
+	}
+
+	if err = t.setRelationships(t.m.getRelationships(moved), newEntryID); err != nil {
+		return
+	}
+
+	if err = t.setLookups(getLookups(moved), newEntryID); err != nil {
+		return
+	}
+
+	for _, l := range ls {
+		if err = t.trackManualLookup([]byte(l.Key), []byte(l.ID), newEntryID); err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,696 @@
+package mojura
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mojura/backend"
+	"github.com/mojura/kiroku"
+	"github.com/mojura/mojura/action"
+	"github.com/mojura/mojura/filters"
+)
+
+func TestReplay_chunks(t *testing.T) {
+	h := newTestReplayHarness(t)
+	defer h.teardown()
+
+	var ids []string
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		for i := 0; i < 12; i++ {
+			var created *testLookupStruct
+			if created, err = txn.New(newTestReplayStruct(i, i)); err != nil {
+				return
+			}
+
+			ids = append(ids, created.ID)
+		}
+
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		// Move entries between relationship IDs
+		for i := 0; i < 6; i++ {
+			if _, err = txn.Put(ids[i], newTestReplayStruct(i, i+1)); err != nil {
+				return
+			}
+		}
+
+		for _, entryID := range []string{ids[1], ids[4], ids[7], ids[10]} {
+			if _, err = txn.Delete(entryID); err != nil {
+				return
+			}
+		}
+
+		return
+	})
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		// Delete an entry which was updated within a previous chunk, then re-use it's lookup
+		if _, err = txn.Delete(ids[2]); err != nil {
+			return
+		}
+
+		replacement := newTestReplayStruct(2, 3)
+		_, err = txn.New(replacement)
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+
+	// Chunks may be imported more than once, replays must not change the state
+	h.replay(kiroku.TypeChunk)
+	h.compare()
+}
+
+func TestReplay_snapshot(t *testing.T) {
+	h := newTestReplayHarness(t)
+	defer h.teardown()
+
+	var ids []string
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		for i := 0; i < 8; i++ {
+			var created *testLookupStruct
+			if created, err = txn.New(newTestReplayStruct(i, i)); err != nil {
+				return
+			}
+
+			ids = append(ids, created.ID)
+		}
+
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+
+	// The mirror misses this chunk and will catch up through a snapshot
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		for i := 0; i < 4; i++ {
+			if _, err = txn.Put(ids[i], newTestReplayStruct(i, i+2)); err != nil {
+				return
+			}
+		}
+
+		if _, err = txn.Delete(ids[5]); err != nil {
+			return
+		}
+
+		_, err = txn.Delete(ids[6])
+		return
+	})
+
+	h.blocks = h.blocks[:0]
+	h.snapshot()
+	h.sync(kiroku.TypeSnapshot)
+	h.compare()
+
+	// The mirror missed the changes of the skipped chunk, the snapshot carries their position
+	if expected, received := uint64(14), h.mirror.ReplicationStatus().LastImportedPosition; expected != received {
+		t.Fatalf("invalid last imported position, expected %d and received %d", expected, received)
+	}
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		if _, err = txn.Delete(ids[0]); err != nil {
+			return
+		}
+
+		_, err = txn.New(newTestReplayStruct(9, 1))
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+}
+
+func TestReplay_lookups(t *testing.T) {
+	h := newTestReplayHarness(t)
+	defer h.teardown()
+
+	var ids []string
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		for i := 0; i < 6; i++ {
+			var created *testLookupStruct
+			if created, err = txn.New(newTestReplayStruct(i, i)); err != nil {
+				return
+			}
+
+			if err = txn.SetLookup("username", fmt.Sprintf("username_%d", i), created.ID); err != nil {
+				return
+			}
+
+			ids = append(ids, created.ID)
+		}
+
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		if err = txn.RemoveLookup("username", "username_1"); err != nil {
+			return
+		}
+
+		// Manual lookups are removed along with their entry
+		_, err = txn.Delete(ids[2])
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+
+	if _, err := h.mirror.GetByLookup("username", "username_0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mirror misses this chunk and will catch up through a snapshot
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		if err = txn.SetLookup("username", "username_7", ids[3]); err != nil {
+			return
+		}
+
+		return txn.RemoveLookup("username", "username_4")
+	})
+
+	h.blocks = h.blocks[:0]
+	h.snapshot()
+	h.sync(kiroku.TypeSnapshot)
+	h.compare()
+
+	if _, err := h.mirror.GetByLookup("username", "username_7"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplay_soft_delete(t *testing.T) {
+	h := newTestReplayHarness(t, func(o *Opts) { o.SoftDelete = true })
+	defer h.teardown()
+
+	var ids []string
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		for i := 0; i < 6; i++ {
+			var created *testLookupStruct
+			if created, err = txn.New(newTestReplayStruct(i, i)); err != nil {
+				return
+			}
+
+			ids = append(ids, created.ID)
+		}
+
+		// Manual lookups are retained within tombstones
+		for _, i := range []int{0, 3} {
+			if err = txn.SetLookup("username", fmt.Sprintf("username_%d", i), ids[i]); err != nil {
+				return
+			}
+		}
+
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		for _, entryID := range ids[:3] {
+			if _, err = txn.Delete(entryID); err != nil {
+				return
+			}
+		}
+
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+	h.compareTombstones()
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		_, err = txn.Restore(ids[0])
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+	h.compareTombstones()
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		var n int64
+		if n, err = txn.Purge(time.Now()); err == nil && n != 2 {
+			err = fmt.Errorf("invalid number of purged entries, expected %d and received %d", 2, n)
+		}
+
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+	h.compareTombstones()
+
+	// The mirror misses this chunk and will catch up through a snapshot
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		_, err = txn.Delete(ids[3])
+		return
+	})
+
+	h.blocks = h.blocks[:0]
+	h.snapshot()
+	h.sync(kiroku.TypeSnapshot)
+	h.compare()
+	// Tombstones imported from a snapshot retain the deletion time and manual lookups of the primary
+	h.compareTombstones()
+
+	if tombstones := h.getTombstones(h.mirror); len(tombstones) != 1 {
+		t.Fatalf("invalid number of tombstones, expected %d and received %d", 1, len(tombstones))
+	}
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		_, err = txn.Restore(ids[3])
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+	h.compareTombstones()
+
+	for _, c := range []*Mojura[*testLookupStruct]{h.primary, h.mirror} {
+		// Manual lookups are set once again when restored
+		for _, i := range []int{0, 3} {
+			if val, err := c.GetByLookup("username", fmt.Sprintf("username_%d", i)); err != nil {
+				t.Fatal(err)
+			} else if val.ID != ids[i] {
+				t.Fatalf("invalid lookup, expected <%s> and received <%s>", ids[i], val.ID)
+			}
+		}
+	}
+}
+
+func TestReplay_rekey(t *testing.T) {
+	h := newTestReplayHarness(t, func(o *Opts) {
+		o.IndexLength = 2
+		o.IndexHistory = true
+		o.SoftDelete = true
+	})
+	defer h.teardown()
+
+	var ids []string
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		for i := 0; i < 6; i++ {
+			var created *testLookupStruct
+			if created, err = txn.New(newTestReplayStruct(i, i)); err != nil {
+				return
+			}
+
+			if err = txn.SetLookup("username", fmt.Sprintf("username_%d", i), created.ID); err != nil {
+				return
+			}
+
+			ids = append(ids, created.ID)
+		}
+
+		_, err = txn.Delete(ids[5])
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		current := txn.getIDGenerator().(*SequentialIDGenerator)
+		var n int64
+		if n, err = txn.rekey(current, NewSequentialIDGenerator(4)); err == nil && n != 5 {
+			err = fmt.Errorf("invalid number of rekeyed entries, expected %d and received %d", 5, n)
+		}
+
+		return
+	})
+
+	if g := h.primary.getIDGenerator().(*SequentialIDGenerator); g.IndexLength() != 4 {
+		t.Fatalf("invalid index length, expected %d and received %d", 4, g.IndexLength())
+	}
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+	h.compareTombstones()
+
+	if tombstones := h.getTombstones(h.mirror); len(tombstones) != 1 || tombstones[0][:5] != "0005:" {
+		t.Fatalf("invalid tombstones, expected the tombstone of <%s> and received %v", "0005", tombstones)
+	}
+
+	for _, c := range []*Mojura[*testLookupStruct]{h.primary, h.mirror} {
+		// Manual lookups are moved along with their entry
+		if val, err := c.GetByLookup("username", "username_1"); err != nil {
+			t.Fatal(err)
+		} else if val.ID != "0001" {
+			t.Fatalf("invalid lookup, expected <%s> and received <%s>", "0001", val.ID)
+		}
+
+		// History is moved along with the entry
+		if revisions := h.getRevisions(c, "0001"); len(revisions) != 1 {
+			t.Fatalf("invalid number of revisions, expected %d and received %d", 1, len(revisions))
+		}
+
+		if revisions := h.getRevisions(c, ids[1]); len(revisions) != 0 {
+			t.Fatalf("invalid number of revisions, expected %d and received %d", 0, len(revisions))
+		}
+	}
+
+	// Mirrors learn the index length from the moved entry IDs, which is applied on promotion
+	if err := h.mirror.importTransaction(context.Background(), func(txn *Transaction[*testLookupStruct]) error {
+		return txn.restoreIndex()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if g := h.mirror.getIDGenerator().(*SequentialIDGenerator); g.IndexLength() != 4 {
+		t.Fatalf("invalid index length, expected %d and received %d", 4, g.IndexLength())
+	}
+}
+
+func TestReplay_history(t *testing.T) {
+	h := newTestReplayHarness(t, func(o *Opts) {
+		o.IndexHistory = true
+	})
+	defer h.teardown()
+
+	var ids []string
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		txn.SetActor("actor_1")
+		txn.SetReason("created")
+		for i := 0; i < 2; i++ {
+			var created *testLookupStruct
+			if created, err = txn.New(newTestReplayStruct(i, i)); err != nil {
+				return
+			}
+
+			ids = append(ids, created.ID)
+		}
+
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+
+	// The mirror misses this chunk and will catch up through a snapshot
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		txn.SetActor("actor_2")
+		_, err = txn.Put(ids[0], newTestReplayStruct(0, 1))
+		return
+	})
+
+	h.blocks = h.blocks[:0]
+	h.snapshot()
+	h.sync(kiroku.TypeSnapshot)
+
+	h.write(func(txn *Transaction[*testLookupStruct]) (err error) {
+		txn.SetActor("actor_3")
+		txn.SetReason("removed")
+		_, err = txn.Delete(ids[0])
+		return
+	})
+
+	h.sync(kiroku.TypeChunk)
+	h.compare()
+
+	expected := []string{"1:write:actor_1:created", "3:write:actor_2:", "4:delete:actor_3:removed"}
+	if revisions := h.getRevisions(h.primary, ids[0]); !slices.Equal(expected, revisions) {
+		t.Fatalf("invalid revisions, expected %v and received %v", expected, revisions)
+	}
+
+	// Snapshots contain the state of the entries rather than revisions, so the revisions
+	// indexed prior to the snapshot are cleared
+	expected = []string{"4:delete:actor_3:removed"}
+	if revisions := h.getRevisions(h.mirror, ids[0]); !slices.Equal(expected, revisions) {
+		t.Fatalf("invalid revisions, expected %v and received %v", expected, revisions)
+	}
+}
+
+func newTestReplayHarness(t *testing.T, optFns ...func(*Opts)) *testReplayHarness {
+	var (
+		h   testReplayHarness
+		err error
+	)
+
+	h.t = t
+	if err = os.MkdirAll(testDir, 0744); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := MakeOpts("test_replay", testDir)
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if h.primary, err = New[*testLookupStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	opts = MakeOpts("test_replay_mirror", testDir)
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	opts.IsMirror = true
+	if h.mirror, err = New[*testLookupStruct](opts, "users", "contacts", "groups", "tags"); err != nil {
+		h.primary.Close()
+		os.RemoveAll(testDir)
+		t.Fatal(err)
+	}
+
+	return &h
+}
+
+// testReplayHarness captures the history of a primary and replays it into a mirror
+type testReplayHarness struct {
+	t *testing.T
+
+	primary *Mojura[*testLookupStruct]
+	mirror  *Mojura[*testLookupStruct]
+
+	blocks testBlockWriter
+}
+
+// write will run a write transaction on the primary and capture the written blocks
+func (h *testReplayHarness) write(fn TransactionFn[*testLookupStruct]) {
+	var next *SequentialIDGenerator
+	if err := h.primary.db.Transaction(func(btxn backend.Transaction) (err error) {
+		var txn Transaction[*testLookupStruct]
+		txn, err = h.primary.runTransaction(context.Background(), btxn, &h.blocks, fn)
+		next = txn.nextIDGenerator
+		return
+	}); err != nil {
+		h.t.Fatal(err)
+	}
+
+	h.primary.switchIDGenerator(next)
+}
+
+// snapshot will capture the current entries of the primary as blocks
+func (h *testReplayHarness) snapshot() {
+	if err := h.primary.ReadTransaction(context.Background(), func(txn *Transaction[*testLookupStruct]) (err error) {
+		var bkt backend.Bucket
+		if bkt, err = txn.getEntriesBucket(); err != nil {
+			return
+		}
+
+		if err = txn.copyPosition(&h.blocks); err != nil {
+			return
+		}
+
+		aw := action.MakeWriter(&h.blocks)
+		if err = bkt.ForEach(func(key, value []byte) (err error) {
+			return aw.Write(key, value)
+		}); err != nil {
+			return
+		}
+
+		if err = txn.copyManualLookups(&h.blocks); err != nil {
+			return
+		}
+
+		if !h.primary.opts.SoftDelete {
+			return
+		}
+
+		return txn.copyTombstones(&h.blocks)
+	}); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// sync will import the captured blocks into the mirror and reset the captured blocks
+func (h *testReplayHarness) sync(t kiroku.Type) {
+	h.replay(t)
+	h.blocks = h.blocks[:0]
+}
+
+func (h *testReplayHarness) replay(t kiroku.Type) {
+	r, err := h.blocks.reader()
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
+	if err = h.mirror.onImport(t, r); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// compare will ensure the mirror returns the same results as the primary
+func (h *testReplayHarness) compare() {
+	fs := []Filter{nil}
+	for i := 0; i < 12; i++ {
+		fs = append(fs,
+			filters.Match("users", fmt.Sprintf("user_%d", i)),
+			filters.Match("groups", fmt.Sprintf("group_%d", i%3)),
+			filters.Match("tags", fmt.Sprintf("tag_%d", i)),
+			filters.InverseMatch("groups", fmt.Sprintf("group_%d", i%3)),
+		)
+	}
+
+	fs = append(fs, filters.Range("users", "user_2", "user_6"))
+
+	for _, f := range fs {
+		o := NewFilteringOpts()
+		if f != nil {
+			o.Filters = []Filter{f}
+		}
+
+		expected := h.getResults(h.primary, o)
+		if received := h.getResults(h.mirror, o); !slices.Equal(expected, received) {
+			h.t.Fatalf("invalid results for filter %+v, expected %v and received %v", f, expected, received)
+		}
+	}
+
+	for i := 0; i < 12; i++ {
+		h.compareLookup("email", newTestReplayStruct(i, 0).Email)
+		h.compareLookup("username", fmt.Sprintf("username_%d", i))
+	}
+}
+
+func (h *testReplayHarness) compareLookup(lookupKey, lookupID string) {
+	expected, expectedErr := h.primary.GetByLookup(lookupKey, lookupID)
+	received, receivedErr := h.mirror.GetByLookup(lookupKey, lookupID)
+	switch {
+	case expectedErr != receivedErr:
+		h.t.Fatalf("invalid lookup error for <%s>, expected <%v> and received <%v>", lookupID, expectedErr, receivedErr)
+	case expectedErr == nil && expected.ID != received.ID:
+		h.t.Fatalf("invalid lookup for <%s>, expected <%s> and received <%s>", lookupID, expected.ID, received.ID)
+	}
+}
+
+// compareTombstones will ensure the mirror has the same tombstones as the primary
+func (h *testReplayHarness) compareTombstones() {
+	expected := h.getTombstones(h.primary)
+	if received := h.getTombstones(h.mirror); !slices.Equal(expected, received) {
+		h.t.Fatalf("invalid tombstones, expected %v and received %v", expected, received)
+	}
+}
+
+func (h *testReplayHarness) getTombstones(c *Mojura[*testLookupStruct]) (tombstones []string) {
+	if err := c.ReadTransaction(context.Background(), func(txn *Transaction[*testLookupStruct]) (err error) {
+		var bkt backend.Bucket
+		if bkt, err = txn.getTombstonesBucket(); err != nil {
+			return
+		}
+
+		return bkt.ForEach(func(entryID, record []byte) (err error) {
+			var ts tombstone
+			if err = ts.unmarshal(record); err != nil {
+				return
+			}
+
+			var val *testLookupStruct
+			if val, err = txn.getTombstoneValue(entryID, &ts); err != nil {
+				return
+			}
+
+			tombstones = append(tombstones, fmt.Sprintf("%s:%s:%d:%v", entryID, val.Value, ts.deletedAt.UnixNano(), ts.lookups))
+			return
+		})
+	}); err != nil {
+		h.t.Fatal(err)
+	}
+
+	return
+}
+
+// getRevisions will return the indexed revisions of an entry as position:type:actor:reason
+func (h *testReplayHarness) getRevisions(c *Mojura[*testLookupStruct], entryID string) (revisions []string) {
+	if err := c.ReadTransaction(context.Background(), func(txn *Transaction[*testLookupStruct]) (err error) {
+		var bkt backend.Bucket
+		if bkt, err = txn.getHistoryBucket([]byte(entryID), false); err != nil || bkt == nil {
+			return
+		}
+
+		return bkt.ForEach(func(key, record []byte) (err error) {
+			var r revisionRecord
+			if err = r.unmarshal(record); err != nil {
+				return
+			}
+
+			md := r.metadata
+			revisions = append(revisions, fmt.Sprintf("%d:%v:%s:%s", md.Position, r.actionType, md.ActorID, md.Reason))
+			return
+		})
+	}); err != nil {
+		h.t.Fatal(err)
+	}
+
+	return
+}
+
+func (h *testReplayHarness) getResults(c *Mojura[*testLookupStruct], o *FilteringOpts) (results []string) {
+	ids, _, err := c.GetFilteredIDs(o)
+	if err != nil && err != ErrEntryNotFound {
+		h.t.Fatal(err)
+	}
+
+	var n int64
+	if n, err = c.Count(o); err != nil {
+		h.t.Fatal(err)
+	}
+
+	results = append(results, fmt.Sprintf("count:%d", n))
+	for _, entryID := range ids {
+		var val *testLookupStruct
+		if val, err = c.Get(entryID); err != nil {
+			h.t.Fatalf("error getting filtered entry <%s>: %v", entryID, err)
+		}
+
+		results = append(results, fmt.Sprintf("%s:%s:%d", entryID, val.Value, val.Version))
+	}
+
+	return
+}
+
+func (h *testReplayHarness) teardown() {
+	if err := h.mirror.Close(); err != nil {
+		h.t.Fatal(err)
+	}
+
+	if err := h.primary.Close(); err != nil {
+		h.t.Fatal(err)
+	}
+
+	os.RemoveAll(testDir)
+}
+
+func newTestReplayStruct(user, group int) *testLookupStruct {
+	var val testLookupStruct
+	val.testStruct = makeTestStruct(
+		fmt.Sprintf("user_%d", user),
+		"contact_1",
+		fmt.Sprintf("group_%d", group%3),
+		fmt.Sprintf("value_%d_%d", user, group),
+		fmt.Sprintf("tag_%d", group),
+	)
+
+	val.Email = fmt.Sprintf("user_%d@example.com", user)
+	return &val
+}
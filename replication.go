@@ -0,0 +1,126 @@
+package mojura
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mojura/kiroku"
+)
+
+// ReplicationStatus represents the import state of an instance
+type ReplicationStatus struct {
+	// LastImportedAt is the creation time of the last imported chunk or snapshot
+	LastImportedAt time.Time `json:"lastImportedAt"`
+	// LastImportedType is the type of the last imported chunk or snapshot
+	LastImportedType kiroku.Type `json:"lastImportedType"`
+	// LastImportedPosition is the position of the last change imported from the primary
+	LastImportedPosition uint64 `json:"lastImportedPosition"`
+	// LastImportCompletedAt is when the last import was committed
+	LastImportCompletedAt time.Time `json:"lastImportCompletedAt"`
+	// SinceLastImport is the duration since the last import was committed
+	SinceLastImport time.Duration `json:"sinceLastImport"`
+	// BlocksApplied is the number of blocks applied since the instance was opened
+	BlocksApplied int64 `json:"blocksApplied"`
+	// LastError is the last replication error, cleared by the next successful import
+	LastError error `json:"-"`
+}
+
+func newReplicationState() *replicationState {
+	var r replicationState
+	r.updated = make(chan struct{})
+	return &r
+}
+
+type replicationState struct {
+	mux sync.RWMutex
+
+	status ReplicationStatus
+	// updated is closed and replaced whenever an import is committed
+	updated chan struct{}
+}
+
+func (r *replicationState) get() (status ReplicationStatus) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	status = r.status
+	if !status.LastImportCompletedAt.IsZero() {
+		status.SinceLastImport = time.Since(status.LastImportCompletedAt)
+	}
+
+	return
+}
+
+// setPosition will set the position of the last imported change
+func (r *replicationState) setPosition(position uint64) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.status.LastImportedPosition = position
+}
+
+func (r *replicationState) onImport(t kiroku.Type, createdAt int64, position uint64, blocks int) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if createdAt > 0 {
+		r.status.LastImportedAt = time.Unix(0, createdAt)
+	}
+
+	r.status.LastImportedType = t
+	r.status.LastImportedPosition = position
+	r.status.LastImportCompletedAt = time.Now()
+	r.status.BlocksApplied += int64(blocks)
+	r.status.LastError = nil
+
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
+
+func (r *replicationState) onError(err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.status.LastError = err
+}
+
+// wait will wait until the change at the position has been imported
+func (r *replicationState) wait(ctx context.Context, position uint64) (err error) {
+	for {
+		r.mux.RLock()
+		caughtUp := r.status.LastImportedPosition >= position
+		updated := r.updated
+		r.mux.RUnlock()
+
+		if caughtUp {
+			return
+		}
+
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// getChunkTimestamp will return the creation time of the chunk being read, the creation
+// time is parsed from the chunk filename (<name>.<created at>.<type>.kir)
+func getChunkTimestamp(r *kiroku.Reader) (createdAt int64, ok bool) {
+	named, ok := r.ReadSeeker().(interface{ Name() string })
+	if !ok {
+		return
+	}
+
+	spl := strings.Split(filepath.Base(named.Name()), ".")
+	if len(spl) < 4 {
+		return 0, false
+	}
+
+	var err error
+	if createdAt, err = strconv.ParseInt(spl[len(spl)-3], 10, 64); err != nil {
+		return 0, false
+	}
+
+	return createdAt, true
+}
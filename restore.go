@@ -0,0 +1,141 @@
+package mojura
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"time"
+
+	"github.com/mojura/enkodo"
+	"github.com/mojura/kiroku"
+	"github.com/mojura/mojura/action"
+)
+
+// restore will build a database within dir by replaying the history exported to the Source.
+// Blocks written after the range end are not replayed, a zero range end replays all blocks
+func (m *Mojura[T]) restore(ctx context.Context, dir string, rangeEnd time.Time, blockLimit int64) (restored *Mojura[T], err error) {
+	src := m.opts.Source
+	if src == nil {
+		err = ErrSourceNotSet
+		return
+	}
+
+	opts := *m.opts
+	opts.Dir = dir
+	opts.IsMirror = true
+	opts.RangeEnd = rangeEnd
+	// The Source is unset so the restored instance will not start a consumer of it's own
+	opts.Source = nil
+	opts.OnImport = nil
+	opts.OnImportEntries = nil
+
+	if _, err = os.Stat(path.Join(dir, opts.FullName()+".bdb")); err == nil {
+		err = ErrRestoreTargetExists
+		return
+	} else if !os.IsNotExist(err) {
+		return
+	}
+
+	if err = os.MkdirAll(dir, 0744); err != nil {
+		return
+	}
+
+	if restored, err = New[T](opts, m.getRelationshipKeys()...); err != nil {
+		return
+	}
+
+	onUpdate := restored.onImport
+	if !rangeEnd.IsZero() {
+		// Chunks created after the range end are skipped by the consumer, the blocks of the
+		// chunk which spans the range end are filtered by their timestamp
+		onUpdate = newRangeEndUpdateFunc(onUpdate, rangeEnd)
+	}
+
+	if blockLimit >= 0 {
+		onUpdate = newBlockLimitedUpdateFunc(onUpdate, blockLimit)
+	}
+
+	if err = kiroku.NewOneShotConsumerWithContext(ctx, restored.opts.Options, src, onUpdate); err != nil {
+		restored.Close()
+		restored = nil
+		return
+	}
+
+	return
+}
+
+// getRelationshipKeys will return the relationship keys the instance was opened with
+func (m *Mojura[T]) getRelationshipKeys() (relationships []string) {
+	if len(m.relationshipFields) > 0 {
+		// Relationships are derived from the tagged fields
+		return
+	}
+
+	for i, relationship := range m.relationships {
+		relationshipKey := string(relationship)
+		if m.uniqueRelationships[i] {
+			relationshipKey = Unique(relationshipKey)
+		}
+
+		relationships = append(relationships, relationshipKey)
+	}
+
+	return
+}
+
+// newBlockLimitedUpdateFunc will wrap an update func so only the first blocks of history
+// are processed, up to the block limit
+func newBlockLimitedUpdateFunc(onUpdate kiroku.UpdateFunc, blockLimit int64) kiroku.UpdateFunc {
+	var processed int64
+	return func(t kiroku.Type, r *kiroku.Reader) (err error) {
+		if processed >= blockLimit {
+			return
+		}
+
+		var buf bytes.Buffer
+		w := enkodo.NewWriter(&buf)
+		if err = r.ForEach(0, func(b kiroku.Block) (err error) {
+			if processed >= blockLimit {
+				return Break
+			}
+
+			processed++
+			return w.Encode(b)
+		}); err != nil && err != Break {
+			return
+		}
+
+		return onUpdate(t, kiroku.NewReader(bytes.NewReader(buf.Bytes())))
+	}
+}
+
+// newRangeEndUpdateFunc will wrap an update func so the blocks of chunks written after the
+// range end are not processed. Blocks written without a timestamp are always processed
+// Note: Snapshots are processed as-is, as their blocks contain the state at the snapshot
+func newRangeEndUpdateFunc(onUpdate kiroku.UpdateFunc, rangeEnd time.Time) kiroku.UpdateFunc {
+	return func(t kiroku.Type, r *kiroku.Reader) (err error) {
+		if t != kiroku.TypeChunk {
+			return onUpdate(t, r)
+		}
+
+		var buf bytes.Buffer
+		w := enkodo.NewWriter(&buf)
+		if err = r.ForEach(0, func(b kiroku.Block) (err error) {
+			var a action.Action
+			if err = enkodo.NewReader(bytes.NewReader(b)).Decode(&a); err != nil {
+				return
+			}
+
+			if a.Metadata.Timestamp.After(rangeEnd) {
+				return
+			}
+
+			return w.Encode(b)
+		}); err != nil {
+			return
+		}
+
+		return onUpdate(t, kiroku.NewReader(bytes.NewReader(buf.Bytes())))
+	}
+}
@@ -0,0 +1,223 @@
+package mojura
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mojura/backend"
+)
+
+func (m *Mojura[T]) initSchema() (err error) {
+	return m.importTransaction(context.Background(), func(txn *Transaction[T]) (err error) {
+		if err = txn.migrateSchema(); err != nil {
+			return
+		}
+
+		var hasEntries bool
+		if hasEntries, err = m.hasEntries(txn); err != nil || hasEntries {
+			return
+		}
+
+		if current := txn.getCurrentSchemaVersion(); txn.meta.SchemaVersion != current {
+			// Database is empty, all entries will be written at the current schema version
+			txn.meta.SchemaVersion = current
+			txn.metaUpdated = true
+		}
+
+		return
+	})
+}
+
+// migrateSchema will compare the relationships stored within the meta against the current
+// relationships. Renamed relationships are moved, removed relationships are dropped and
+// added relationships are backfilled from the existing entries
+func (t *Transaction[T]) migrateSchema() (err error) {
+	current := make([]string, 0, len(t.m.relationships))
+	for _, relationship := range t.m.relationships {
+		current = append(current, string(relationship))
+	}
+
+	stored := slices.Clone(t.meta.Relationships)
+	if stored == nil {
+		// Schema has not been recorded yet, assume the relationships match the current relationships
+		return t.setSchema(current)
+	}
+
+	for from, to := range t.m.opts.RelationshipRenames {
+		index := slices.Index(stored, from)
+		if index == -1 || slices.Contains(stored, to) || !slices.Contains(current, to) {
+			continue
+		}
+
+		t.m.out.Notificationf("Renaming relationship <%s> to <%s>", from, to)
+		if err = t.renameRelationship([]byte(from), []byte(to)); err != nil {
+			err = fmt.Errorf("error renaming relationship <%s> to <%s>: %v", from, to, err)
+			return
+		}
+
+		stored[index] = to
+	}
+
+	for _, relationship := range stored {
+		if slices.Contains(current, relationship) {
+			continue
+		}
+
+		t.m.out.Notificationf("Removing relationship <%s>", relationship)
+		if err = t.removeRelationship([]byte(relationship)); err != nil {
+			err = fmt.Errorf("error removing relationship <%s>: %v", relationship, err)
+			return
+		}
+	}
+
+	var added []int
+	for i, relationship := range current {
+		if slices.Contains(stored, relationship) {
+			continue
+		}
+
+		t.m.out.Notificationf("Adding relationship <%s>, backfilling from existing entries", relationship)
+		added = append(added, i)
+	}
+
+	if err = t.backfillRelationships(added); err != nil {
+		err = fmt.Errorf("error backfilling relationships: %v", err)
+		return
+	}
+
+	if slices.Equal(t.meta.Relationships, current) {
+		return
+	}
+
+	return t.setSchema(current)
+}
+
+func (t *Transaction[T]) setSchema(relationships []string) (err error) {
+	t.meta.Relationships = relationships
+	t.metaUpdated = true
+	return
+}
+
+func (t *Transaction[T]) renameRelationship(from, to []byte) (err error) {
+	var relationshipsBkt backend.Bucket
+	if relationshipsBkt = t.txn.GetBucket(relationshipsBktKey); relationshipsBkt == nil {
+		return ErrNotInitialized
+	}
+
+	if err = moveBucket(relationshipsBkt, from, to); err != nil {
+		return
+	}
+
+	var countsBkt backend.Bucket
+	if countsBkt, err = t.getCountsBucket(); err != nil {
+		return
+	}
+
+	return moveBucket(countsBkt, from, to)
+}
+
+func (t *Transaction[T]) removeRelationship(relationship []byte) (err error) {
+	var relationshipsBkt backend.Bucket
+	if relationshipsBkt = t.txn.GetBucket(relationshipsBktKey); relationshipsBkt == nil {
+		return ErrNotInitialized
+	}
+
+	if err = deleteBucket(relationshipsBkt, relationship); err != nil {
+		return
+	}
+
+	var countsBkt backend.Bucket
+	if countsBkt, err = t.getCountsBucket(); err != nil {
+		return
+	}
+
+	return deleteBucket(countsBkt, relationship)
+}
+
+// backfillRelationships will set the relationships at the provided indexes for all entries
+func (t *Transaction[T]) backfillRelationships(indexes []int) (err error) {
+	if len(indexes) == 0 {
+		return
+	}
+
+	return t.ForEach(func(entryID string, val T) (err error) {
+		relationships := t.m.getRelationships(val)
+		for _, index := range indexes {
+			if index >= len(relationships) {
+				continue
+			}
+
+			for _, relationshipID := range relationships[index] {
+				if err = t.setRelationship(t.m.relationships[index], []byte(relationshipID), []byte(entryID)); err != nil {
+					return
+				}
+			}
+		}
+
+		return
+	}, nil)
+}
+
+// moveBucket will move the contents of a bucket (including nested buckets) to another
+// bucket within the same parent
+func moveBucket(parent backend.Bucket, from, to []byte) (err error) {
+	src := parent.GetBucket(from)
+	if src == nil {
+		return
+	}
+
+	var dst backend.Bucket
+	if dst, err = parent.GetOrCreateBucket(to); err != nil {
+		return
+	}
+
+	if err = copyBucket(src, dst); err != nil {
+		return
+	}
+
+	return parent.DeleteBucket(from)
+}
+
+func copyBucket(src, dst backend.Bucket) (err error) {
+	cur := src.Cursor()
+	for key, value := cur.First(); key != nil; key, value = cur.Next() {
+		if value != nil {
+			if err = dst.Put(bytes.Clone(key), bytes.Clone(value)); err != nil {
+				return
+			}
+
+			continue
+		}
+
+		nested := src.GetBucket(key)
+		if nested == nil {
+			// Key has an empty value
+			if err = dst.Put(bytes.Clone(key), nil); err != nil {
+				return
+			}
+
+			continue
+		}
+
+		var nestedDst backend.Bucket
+		if nestedDst, err = dst.GetOrCreateBucket(bytes.Clone(key)); err != nil {
+			return
+		}
+
+		if err = copyBucket(nested, nestedDst); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func deleteBucket(parent backend.Bucket, key []byte) (err error) {
+	if parent.GetBucket(key) == nil {
+		return
+	}
+
+	return parent.DeleteBucket(key)
+}
@@ -0,0 +1,372 @@
+package mojura
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/mojura/backend"
+	"github.com/mojura/enkodo"
+	"github.com/mojura/mojura/action"
+)
+
+// tombstoneHeaderSize is the size of a tombstone header: deletion timestamp (8), schema version (8), value length (8)
+const tombstoneHeaderSize = 24
+
+// tombstone is the record of a soft-deleted entry
+type tombstone struct {
+	deletedAt time.Time
+	// schemaVersion is the schema version the value was written with
+	schemaVersion int64
+	value         []byte
+	// lookups are the manual lookups of the entry, which are set once again when restored
+	lookups []action.Lookup
+}
+
+func (ts *tombstone) marshal() (record []byte, err error) {
+	buf := bytes.NewBuffer(make([]byte, tombstoneHeaderSize, tombstoneHeaderSize+len(ts.value)))
+	buf.Write(ts.value)
+	w := enkodo.NewWriter(buf)
+	for i := range ts.lookups {
+		if err = w.Encode(&ts.lookups[i]); err != nil {
+			return
+		}
+	}
+
+	record = buf.Bytes()
+	binary.BigEndian.PutUint64(record[0:8], uint64(ts.deletedAt.UnixNano()))
+	binary.BigEndian.PutUint64(record[8:16], uint64(ts.schemaVersion))
+	binary.BigEndian.PutUint64(record[16:24], uint64(len(ts.value)))
+	return
+}
+
+func (ts *tombstone) unmarshal(record []byte) (err error) {
+	if len(record) < tombstoneHeaderSize {
+		return ErrInvalidTombstone
+	}
+
+	ts.deletedAt = getDeletedAt(record)
+	ts.schemaVersion = int64(binary.BigEndian.Uint64(record[8:16]))
+	valueEnd := tombstoneHeaderSize + binary.BigEndian.Uint64(record[16:24])
+	if valueEnd > uint64(len(record)) {
+		return ErrInvalidTombstone
+	}
+
+	ts.value = record[tombstoneHeaderSize:valueEnd]
+	r := enkodo.NewReader(bytes.NewReader(record[valueEnd:]))
+	for {
+		var l action.Lookup
+		switch err = r.Decode(&l); err {
+		case nil:
+			ts.lookups = append(ts.lookups, l)
+		case io.EOF:
+			return nil
+
+		default:
+			return
+		}
+	}
+}
+
+// getDeletedAt will return the deletion time of a tombstone record
+func getDeletedAt(record []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(record[0:8])))
+}
+
+func (t *Transaction[T]) getTombstonesBucket() (bkt backend.Bucket, err error) {
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	if bkt = t.txn.GetBucket(tombstonesBktKey); bkt == nil {
+		err = ErrNotInitialized
+		return
+	}
+
+	return
+}
+
+// getTimestamp will return the timestamp of the block being imported, falling back to the
+// current time. This ensures mirrors record the same deletion time as the primary
+func (t *Transaction[T]) getTimestamp() time.Time {
+	if !t.blockMetadata.Timestamp.IsZero() {
+		return t.blockMetadata.Timestamp
+	}
+
+	return time.Now()
+}
+
+func (t *Transaction[T]) putTombstone(entryID []byte, ts tombstone) (err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getTombstonesBucket(); err != nil {
+		return
+	}
+
+	var record []byte
+	if record, err = ts.marshal(); err != nil {
+		return
+	}
+
+	return bkt.Put(entryID, record)
+}
+
+// newTombstone will return a tombstone for a value written at the current schema version
+func (t *Transaction[T]) newTombstone(bs []byte, deletedAt time.Time, lookups []action.Lookup) (ts tombstone) {
+	ts.deletedAt = deletedAt
+	ts.schemaVersion = t.getCurrentSchemaVersion()
+	ts.value = bs
+	ts.lookups = lookups
+	return
+}
+
+func (t *Transaction[T]) getTombstone(entryID []byte) (ts tombstone, err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getTombstonesBucket(); err != nil {
+		return
+	}
+
+	var record []byte
+	if record = bkt.Get(entryID); len(record) == 0 {
+		err = ErrEntryNotFound
+		return
+	}
+
+	err = ts.unmarshal(record)
+	return
+}
+
+func (t *Transaction[T]) parseTombstone(entryID, record []byte) (val T, deletedAt time.Time, err error) {
+	var ts tombstone
+	if err = ts.unmarshal(record); err != nil {
+		return
+	}
+
+	val, err = t.getTombstoneValue(entryID, &ts)
+	deletedAt = ts.deletedAt
+	return
+}
+
+// getTombstoneValue will return the value of a tombstone, upgraded to the current schema version
+func (t *Transaction[T]) getTombstoneValue(entryID []byte, ts *tombstone) (val T, err error) {
+	var bs []byte
+	if bs, err = t.upgradeFrom(entryID, ts.value, ts.schemaVersion); err != nil {
+		return
+	}
+
+	return t.m.newValueFromBytes(bs)
+}
+
+// deleteTombstone will remove the tombstone of an entry, if one exists
+func (t *Transaction[T]) deleteTombstone(entryID []byte) (err error) {
+	if !t.m.opts.SoftDelete {
+		return
+	}
+
+	var bkt backend.Bucket
+	if bkt, err = t.getTombstonesBucket(); err != nil {
+		return
+	}
+
+	if len(bkt.Get(entryID)) == 0 {
+		return
+	}
+
+	return bkt.Delete(entryID)
+}
+
+func (t *Transaction[T]) restore(entryID []byte) (restored T, err error) {
+	if !t.m.opts.SoftDelete {
+		err = ErrSoftDeleteNotEnabled
+		return
+	}
+
+	var ts tombstone
+	if ts, err = t.getTombstone(entryID); err != nil {
+		return
+	}
+
+	var val T
+	if val, err = t.getTombstoneValue(entryID, &ts); err != nil {
+		return
+	}
+
+	// The entry is restored as it was when deleted, writing the entry removes the tombstone
+	if restored, err = t.write(entryID, func(_ T) (T, error) { return val, nil }, true, writeModeRestore); err != nil {
+		return
+	}
+
+	for _, l := range ts.lookups {
+		// Lookups are set with their own actions, so mirrors set them as well
+		if err = t.setManualLookup([]byte(l.Key), []byte(l.ID), entryID); err != nil {
+			err = fmt.Errorf("error restoring lookup <%s> <%s>: %v", l.Key, l.ID, err)
+			return
+		}
+	}
+
+	return
+}
+
+func (t *Transaction[T]) purge(olderThan time.Time) (n int64, err error) {
+	if !t.m.opts.SoftDelete {
+		err = ErrSoftDeleteNotEnabled
+		return
+	}
+
+	var bkt backend.Bucket
+	if bkt, err = t.getTombstonesBucket(); err != nil {
+		return
+	}
+
+	// Entry IDs are collected ahead of any mutations, as deleting during iteration would
+	// invalidate the underlying cursor
+	var entryIDs [][]byte
+	if err = bkt.ForEach(func(entryID, record []byte) (err error) {
+		if len(record) < tombstoneHeaderSize {
+			return ErrInvalidTombstone
+		}
+
+		if getDeletedAt(record).Before(olderThan) {
+			entryIDs = append(entryIDs, append([]byte(nil), entryID...))
+		}
+
+		return
+	}); err != nil {
+		return
+	}
+
+	for _, entryID := range entryIDs {
+		if err = t.purgeTombstone(entryID); err != nil {
+			return
+		}
+
+		n++
+	}
+
+	return
+}
+
+// purgeTombstone will permanently remove the tombstone of an entry and record the purge
+// within the history, so mirrors remove the tombstone as well
+func (t *Transaction[T]) purgeTombstone(entryID []byte) (err error) {
+	if err = t.deleteTombstone(entryID); err != nil {
+		return
+	}
+
+	aw := t.newActionWriter(time.Now())
+	return aw.Purge(entryID)
+}
+
+// copyTombstones will write the tombstones to a snapshot as deletes containing the deleted
+// value followed by the manual lookups, so mirrors are able to rebuild the tombstones from a snapshot
+func (t *Transaction[T]) copyTombstones(bw action.BlockWriter) (err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getTombstonesBucket(); err != nil {
+		return
+	}
+
+	return bkt.ForEach(func(entryID, record []byte) (err error) {
+		var ts tombstone
+		if err = ts.unmarshal(record); err != nil {
+			return
+		}
+
+		var val T
+		if val, err = t.getTombstoneValue(entryID, &ts); err != nil {
+			return
+		}
+
+		var bs []byte
+		if bs, err = t.m.marshal(val); err != nil {
+			return
+		}
+
+		aw := action.MakeWriter(bw)
+		aw.SetMetadata(action.Metadata{Timestamp: ts.deletedAt})
+		if err = aw.DeleteValue(entryID, bs); err != nil {
+			return
+		}
+
+		for _, l := range ts.lookups {
+			if err = aw.SetLookup(entryID, l); err != nil {
+				return
+			}
+		}
+
+		return
+	})
+}
+
+// importTombstone will create a tombstone from an imported delete of an entry which does
+// not exist, which is how tombstones are included within snapshots
+func (t *Transaction[T]) importTombstone(a *action.Action) (err error) {
+	if !t.m.opts.SoftDelete || len(a.Value) == 0 {
+		return
+	}
+
+	// Snapshot values are written at the current schema version
+	return t.putTombstone(a.Key, t.newTombstone(a.Value, t.getTimestamp(), nil))
+}
+
+// importTombstoneLookup will add an imported manual lookup to the tombstone of an entry, if one
+// exists. Snapshots include the manual lookups of a tombstone following the tombstone
+func (t *Transaction[T]) importTombstoneLookup(entryID []byte, l action.Lookup) (err error) {
+	if !t.m.opts.SoftDelete {
+		return
+	}
+
+	var ts tombstone
+	switch ts, err = t.getTombstone(entryID); err {
+	case nil:
+	case ErrEntryNotFound:
+		return nil
+
+	default:
+		return
+	}
+
+	if slices.Contains(ts.lookups, l) {
+		return
+	}
+
+	ts.lookups = append(ts.lookups, l)
+	return t.putTombstone(entryID, ts)
+}
+
+// moveTombstone will move the tombstone of an entry to a new entry ID, if one exists. The
+// deletion time and manual lookups are retained
+func (t *Transaction[T]) moveTombstone(entryID, newEntryID []byte) (err error) {
+	if !t.m.opts.SoftDelete {
+		return
+	}
+
+	var ts tombstone
+	switch ts, err = t.getTombstone(entryID); err {
+	case nil:
+	case ErrEntryNotFound:
+		return nil
+
+	default:
+		return
+	}
+
+	var val T
+	if val, err = t.getTombstoneValue(entryID, &ts); err != nil {
+		return
+	}
+
+	val.SetID(string(newEntryID))
+	var bs []byte
+	if bs, err = t.m.marshal(val); err != nil {
+		return
+	}
+
+	ts = t.newTombstone(bs, ts.deletedAt, ts.lookups)
+	if err = t.putTombstone(newEntryID, ts); err != nil {
+		return
+	}
+
+	return t.deleteTombstone(entryID)
+}
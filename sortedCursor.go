@@ -0,0 +1,142 @@
+package mojura
+
+import (
+	"bytes"
+	"slices"
+)
+
+var _ filterCursor = &sortedCursor[*Entry]{}
+
+// newSortedCursor will collect all of the entry IDs from a filter cursor
+// so they can be iterated in entry ID order
+func newSortedCursor[T Value](txn *Transaction[T], fc filterCursor) (cur *sortedCursor[T], err error) {
+	var c sortedCursor[T]
+	var entryID []byte
+	for entryID, err = fc.First(); err == nil; entryID, err = fc.Next() {
+		c.entryIDs = append(c.entryIDs, bytes.Clone(entryID))
+	}
+
+	if err != Break {
+		return
+	}
+
+	slices.SortFunc(c.entryIDs, bytes.Compare)
+	// Entries can be matched by more than one relationship ID, remove the duplicates
+	c.entryIDs = slices.CompactFunc(c.entryIDs, bytes.Equal)
+
+	c.txn = txn
+	c.index = -1
+	cur = &c
+	err = nil
+	return
+}
+
+type sortedCursor[T Value] struct {
+	txn *Transaction[T]
+
+	entryIDs [][]byte
+	index    int
+}
+
+func (c *sortedCursor[T]) get() (entryID []byte, err error) {
+	if c.index < 0 || c.index >= len(c.entryIDs) {
+		err = Break
+		return
+	}
+
+	entryID = c.entryIDs[c.index]
+	return
+}
+
+func (c *sortedCursor[T]) seek(seekID []byte) (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	c.index, _ = slices.BinarySearchFunc(c.entryIDs, seekID, bytes.Compare)
+	return c.get()
+}
+
+func (c *sortedCursor[T]) has(entryID []byte) (ok bool, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	_, ok = slices.BinarySearchFunc(c.entryIDs, entryID, bytes.Compare)
+	return
+}
+
+func (c *sortedCursor[T]) getCurrentRelationshipID() (relationshipID string) {
+	return ""
+}
+
+// SeekForward will seek the provided ID
+func (c *sortedCursor[T]) SeekForward(relationshipID, seekID []byte) (entryID []byte, err error) {
+	return c.seek(seekID)
+}
+
+// SeekReverse will seek the provided ID
+func (c *sortedCursor[T]) SeekReverse(relationshipID, seekID []byte) (entryID []byte, err error) {
+	return c.seek(seekID)
+}
+
+// First will return the first entry
+func (c *sortedCursor[T]) First() (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	c.index = 0
+	return c.get()
+}
+
+// Last will return the last entry
+func (c *sortedCursor[T]) Last() (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	c.index = len(c.entryIDs) - 1
+	return c.get()
+}
+
+// Next will return the next entry
+func (c *sortedCursor[T]) Next() (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	if c.index < len(c.entryIDs) {
+		c.index++
+	}
+
+	return c.get()
+}
+
+// Prev will return the previous entry
+func (c *sortedCursor[T]) Prev() (entryID []byte, err error) {
+	if err = c.txn.cc.isDone(); err != nil {
+		return
+	}
+
+	if c.index >= 0 {
+		c.index--
+	}
+
+	return c.get()
+}
+
+// HasForward will determine if an entry exists in a forward direction
+func (c *sortedCursor[T]) HasForward(entryID []byte) (ok bool, err error) {
+	return c.has(entryID)
+}
+
+// HasReverse will determine if an entry exists in a reverse direction
+func (c *sortedCursor[T]) HasReverse(entryID []byte) (ok bool, err error) {
+	return c.has(entryID)
+}
+
+func (c *sortedCursor[T]) teardown() {
+	c.txn = nil
+	c.entryIDs = nil
+}
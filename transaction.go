@@ -5,11 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/mojura/backend"
 	"github.com/mojura/enkodo"
 	"github.com/mojura/kiroku"
 	"github.com/mojura/mojura/action"
+	"github.com/mojura/mojura/filters"
 )
 
 func newTransaction[T Value](ctx context.Context, m *Mojura[T], txn backend.Transaction, bw action.BlockWriter) (t Transaction[T]) {
@@ -30,7 +35,25 @@ type Transaction[T Value] struct {
 	bw  action.BlockWriter
 
 	meta        metadata
+	metaLoaded  bool
 	metaUpdated bool
+
+	// changes are published to subscriptions once the transaction has been committed
+	changes []Change[T]
+	// importEvent is set for imports when an ImportEntriesHandler has been provided
+	importEvent *ImportEvent[T]
+
+	// actionMetadata is written with each action block of the transaction
+	actionMetadata action.Metadata
+	// blockMetadata is the metadata of the block being imported
+	blockMetadata action.Metadata
+	// nextIDGenerator is the ID generator of a rekeyed index length, which is used for the
+	// remainder of the transaction and switched to once the transaction has been committed
+	nextIDGenerator *SequentialIDGenerator
+	// chunkCreatedAt is at or before the creation time of the chunk the blocks of the transaction
+	// are written to, or the creation time of the chunk being imported. It's used to locate
+	// revisions within the history
+	chunkCreatedAt int64
 }
 
 func (t *Transaction[T]) getRelationshipBucket(relationship []byte) (bkt backend.Bucket, err error) {
@@ -80,11 +103,7 @@ func (t *Transaction[T]) get(entryID []byte) (val T, err error) {
 		return
 	}
 
-	if err = t.m.unmarshal(bs, &val); err != nil {
-		return
-	}
-
-	return
+	return t.newValueFromBytes(entryID, bs)
 }
 
 func (t *Transaction[T]) getBytes(entryID []byte) (bs []byte, err error) {
@@ -148,11 +167,38 @@ func (t *Transaction[T]) insertEntry(entryID []byte, val T) (err error) {
 		return
 	}
 
+	current := bkt.Get(entryID)
+	isNew := len(current) == 0
+
+	var previous []byte
+	if previous, err = t.getChangePrevious(entryID, current); err != nil {
+		return
+	}
+
 	if err = bkt.Put(entryID, bs); err != nil {
 		return
 	}
 
-	aw := action.MakeWriter(t.bw)
+	// Writing an entry brings it back from the tombstones
+	if err = t.deleteTombstone(entryID); err != nil {
+		return
+	}
+
+	if isNew {
+		t.addEntryCount(1)
+	}
+
+	if err = t.setSchemaVersion(entryID); err != nil {
+		return
+	}
+
+	position := t.addChange(action.TypeWrite, entryID, bs, previous)
+	md := t.newChangeMetadata(time.Now(), position)
+	if err = t.indexRevision(action.TypeWrite, entryID, md); err != nil {
+		return
+	}
+
+	aw := t.newChangeWriter(md)
 	return aw.Write(entryID, bs)
 }
 
@@ -166,7 +212,21 @@ func (t *Transaction[T]) deleteEntry(entryID []byte) (err error) {
 		return ErrNotInitialized
 	}
 
-	return bkt.Delete(entryID)
+	if len(bkt.Get(entryID)) == 0 {
+		// Entry does not exist, nothing to delete
+		return
+	}
+
+	if err = bkt.Delete(entryID); err != nil {
+		return
+	}
+
+	if err = t.unsetSchemaVersion(entryID); err != nil {
+		return
+	}
+
+	t.addEntryCount(-1)
+	return
 }
 
 func (t *Transaction[T]) setRelationships(relationships Relationships, entryID []byte) (err error) {
@@ -208,7 +268,16 @@ func (t *Transaction[T]) setRelationship(relationship, relationshipID, entryID [
 		return
 	}
 
-	return bkt.Put(entryID, nil)
+	if hasKey(bkt, entryID) {
+		// Relationship is already set
+		return
+	}
+
+	if err = bkt.Put(entryID, nil); err != nil {
+		return
+	}
+
+	return t.updateRelationshipCount(relationship, relationshipID, 1)
 }
 
 func (t *Transaction[T]) unsetRelationships(relationships Relationships, entryID []byte) (err error) {
@@ -245,11 +314,20 @@ func (t *Transaction[T]) unsetRelationship(relationship, relationshipID, entryID
 		return
 	}
 
+	if !hasKey(bkt, entryID) {
+		// Relationship is not set
+		return
+	}
+
 	// Delete entry in bucket by entry ID
 	if err = bkt.Delete(entryID); err != nil {
 		return
 	}
 
+	if err = t.updateRelationshipCount(relationship, relationshipID, -1); err != nil {
+		return
+	}
+
 	// Check to see if relationship ID bucket has any entries left
 	if hasEntries(bkt) {
 		// Bucket has entries, return
@@ -260,309 +338,1239 @@ func (t *Transaction[T]) unsetRelationship(relationship, relationshipID, entryID
 	return relationshipBkt.DeleteBucket(relationshipID)
 }
 
-func (t *Transaction[T]) updateRelationships(entryID []byte, orig, new Relationships) (err error) {
+func (t *Transaction[T]) getLookupsBucket() (bkt backend.Bucket, err error) {
 	if err = t.cc.isDone(); err != nil {
 		return
 	}
 
-	for i, relationship := range new {
-		onAdd := func(relationshipID []byte) (err error) {
-			return t.setRelationship(t.m.relationships[i], relationshipID, entryID)
-		}
-
-		onRemove := func(relationshipID []byte) (err error) {
-			return t.unsetRelationship(t.m.relationships[i], relationshipID, entryID)
-		}
-
-		var origR Relationship
-		if orig != nil {
-			origR = orig[i]
-		}
-
-		if err = relationship.delta(origR, onAdd, onRemove); err != nil {
-			return
-		}
+	if bkt = t.txn.GetBucket(lookupsBktKey); bkt == nil {
+		err = ErrNotInitialized
+		return
 	}
 
 	return
 }
 
-// getLast will attempt to get the first entry which matches the provided filters
-// Note: Will return ErrEntryNotFound if no match is found
-func (t *Transaction[T]) getFirst(o *FilteringOpts) (value T, err error) {
-	var cur IDCursor
-	if cur, err = t.idCursor(o.Filters); err != nil {
-		return
-	}
-
-	var entryID string
-	if entryID, err = getFirstID(cur, o.LastID, false); err == Break {
-		err = ErrEntryNotFound
-		return
-	} else if err != nil {
+func (t *Transaction[T]) getLookup(lookupKey, lookupID []byte) (entryID []byte, err error) {
+	var lookupsBkt backend.Bucket
+	if lookupsBkt, err = t.getLookupsBucket(); err != nil {
 		return
 	}
 
-	return t.get([]byte(entryID))
-}
-
-// getLast will attempt to get the last entry which matches the provided filters
-// Note: Will return ErrEntryNotFound if no match is found
-func (t *Transaction[T]) getLast(o *FilteringOpts) (value T, err error) {
-	var cur IDCursor
-	if cur, err = t.idCursor(o.Filters); err != nil {
+	var bkt backend.Bucket
+	if bkt = lookupsBkt.GetBucket(lookupKey); bkt == nil {
+		err = ErrLookupNotFound
 		return
 	}
 
-	var entryID string
-	if entryID, err = getFirstID(cur, o.LastID, true); err == Break {
-		err = ErrEntryNotFound
-		return
-	} else if err != nil {
+	if entryID = bkt.Get(lookupID); len(entryID) == 0 {
+		err = ErrLookupNotFound
 		return
 	}
 
-	return t.get([]byte(entryID))
+	return
 }
 
-func (t *Transaction[T]) getFiltered(o *FilteringOpts) (es []T, lastID string, err error) {
-	return t.appendFiltered(nil, o)
-}
+func (t *Transaction[T]) getByLookup(lookupKey, lookupID []byte) (val T, err error) {
+	var entryID []byte
+	if entryID, err = t.getLookup(lookupKey, lookupID); err != nil {
+		return
+	}
 
-func (t *Transaction[T]) getFilteredIDs(o *FilteringOpts) (IDs []string, lastID string, err error) {
-	return t.appendFilteredIDs(nil, o)
+	return t.get(entryID)
 }
 
-func (t *Transaction[T]) appendFiltered(in []T, o *FilteringOpts) (out []T, lastID string, err error) {
-	if o == nil {
-		o = defaultFilteringOpts
+func (t *Transaction[T]) setLookup(lookupKey, lookupID, entryID []byte) (err error) {
+	if len(lookupKey) == 0 || len(lookupID) == 0 {
+		err = ErrEmptyLookup
+		return
 	}
 
-	if o.Limit == 0 {
+	var lookupsBkt backend.Bucket
+	if lookupsBkt, err = t.getLookupsBucket(); err != nil {
 		return
 	}
 
-	var c Cursor[T]
-	if c, err = t.cursor(o.Filters); err != nil {
+	var bkt backend.Bucket
+	if bkt, err = lookupsBkt.GetOrCreateBucket(lookupKey); err != nil {
+		err = fmt.Errorf("error getting bucket for lookup key <%s>: %v", lookupKey, err)
 		return
 	}
 
-	var count int64
-	out = in
-	err = t.forEachWithCursor(c, o, func(entryID string, val T) (err error) {
-		out = append(out, val)
-		if count++; count == o.Limit {
-			lastID = joinSeekID(c.getCurrentRelationshipID(), entryID)
-			return Break
-		}
+	current := bkt.Get(lookupID)
+	switch {
+	case len(current) == 0:
+	case bytes.Equal(current, entryID):
+		// Lookup is already set for this entry
+		return
 
+	default:
+		// Lookup belongs to another entry
+		err = ErrLookupExists
 		return
-	})
+	}
 
-	return
+	return bkt.Put(lookupID, entryID)
 }
 
-func (t *Transaction[T]) appendFilteredIDs(in []string, o *FilteringOpts) (out []string, lastID string, err error) {
-	if o == nil {
-		o = defaultFilteringOpts
-	}
+func (t *Transaction[T]) setLookups(lookups Lookups, entryID []byte) (err error) {
+	return lookups.delta(nil, func(lookupKey, lookupID []byte) error {
+		return t.setLookup(lookupKey, lookupID, entryID)
+	}, nil)
+}
 
-	if o.Limit == 0 {
+func (t *Transaction[T]) removeLookup(lookupKey, lookupID []byte) (err error) {
+	var lookupsBkt backend.Bucket
+	if lookupsBkt, err = t.getLookupsBucket(); err != nil {
 		return
 	}
 
-	var c Cursor[T]
-	if c, err = t.cursor(o.Filters); err != nil {
+	var bkt backend.Bucket
+	if bkt = lookupsBkt.GetBucket(lookupKey); bkt == nil {
 		return
 	}
 
-	var count int64
-	out = in
-	err = t.forEachWithCursor(c, o, func(entryID string, _ T) (err error) {
-		out = append(out, entryID)
-		if count++; count == o.Limit {
-			lastID = joinSeekID(c.getCurrentRelationshipID(), entryID)
-			return Break
-		}
+	return bkt.Delete(lookupID)
+}
 
+func (t *Transaction[T]) unsetLookup(lookupKey, lookupID, entryID []byte) (err error) {
+	var current []byte
+	current, err = t.getLookup(lookupKey, lookupID)
+	switch {
+	case err == ErrLookupNotFound:
+		return nil
+	case err != nil:
 		return
-	})
+	case !bytes.Equal(current, entryID):
+		// Lookup has been claimed by another entry, leave it in place
+		return
+	}
 
-	return
+	var isManual bool
+	if isManual, err = t.isManualLookup(lookupKey, lookupID, entryID); err != nil || isManual {
+		// Lookup was also set manually, leave it in place
+		return
+	}
+
+	return t.removeLookup(lookupKey, lookupID)
 }
 
-func (t *Transaction[T]) forEachWithCursor(c Cursor[T], o *FilteringOpts, fn ForEachFn[T]) (err error) {
-	var val T
-	iterator := getIteratorFunc(c, o.Reverse)
-	val, err = getFirst(c, o.LastID, o.Reverse)
-	for err == nil {
-		if err = fn(val.GetID(), val); err != nil {
-			break
+func (t *Transaction[T]) unsetLookups(lookups Lookups, entryID []byte) (err error) {
+	for lookupKey, lookupID := range lookups {
+		if len(lookupID) == 0 {
+			continue
 		}
 
-		val, err = iterator()
-	}
-
-	if err == Break {
-		err = nil
+		if err = t.unsetLookup([]byte(lookupKey), []byte(lookupID), entryID); err != nil {
+			return
+		}
 	}
 
 	return
 }
 
-func (t *Transaction[T]) new(val T) (created T, err error) {
+func (t *Transaction[T]) updateLookups(entryID []byte, orig, new Lookups) (err error) {
 	if err = t.cc.isDone(); err != nil {
 		return
 	}
 
-	index := t.meta.CurrentIndex
-	t.setIndex(index + 1)
+	onSet := func(lookupKey, lookupID []byte) (err error) {
+		return t.setLookup(lookupKey, lookupID, entryID)
+	}
 
-	// Create a padded entry ID from index value
-	entryID := []byte(fmt.Sprintf(t.m.indexFmt, index))
+	onRemove := func(lookupKey, lookupID []byte) (err error) {
+		return t.unsetLookup(lookupKey, lookupID, entryID)
+	}
 
-	return t.put(entryID, val)
+	return new.delta(orig, onSet, onRemove)
 }
 
-func (t *Transaction[T]) edit(entryID []byte, fn editFn[T], allowInsert bool) (updated T, err error) {
-	if len(entryID) == 0 {
-		err = ErrEmptyEntryID
+// getManualLookupsBucket will return the bucket of lookups set manually for an entry,
+// keyed by lookup key and lookup ID
+func (t *Transaction[T]) getManualLookupsBucket(entryID []byte, create bool) (bkt backend.Bucket, err error) {
+	if err = t.cc.isDone(); err != nil {
 		return
 	}
 
-	if err = t.cc.isDone(); err != nil {
+	var manualBkt backend.Bucket
+	if manualBkt = t.txn.GetBucket(manualLookupsBktKey); manualBkt == nil {
+		err = ErrNotInitialized
 		return
 	}
 
-	var (
-		orig          T
-		relationships Relationships
-	)
-
-	orig, err = t.get(entryID)
-	switch {
-	case err == nil:
-		relationships = orig.GetRelationships()
-	case err == ErrEntryNotFound && allowInsert:
-	default:
+	if !create {
+		bkt = manualBkt.GetBucket(entryID)
 		return
 	}
 
-	var modified T
-	if modified, err = fn(orig); err != nil {
+	return manualBkt.GetOrCreateBucket(entryID)
+}
+
+func (t *Transaction[T]) getManualLookups(entryID []byte) (ls []action.Lookup, err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getManualLookupsBucket(entryID, false); err != nil || bkt == nil {
 		return
 	}
 
-	setEssetialValues(entryID, modified)
+	err = bkt.ForEach(func(lookupKey, _ []byte) (err error) {
+		keyBkt := bkt.GetBucket(lookupKey)
+		if keyBkt == nil {
+			return
+		}
 
-	// Update relationships (if needed)
-	if err = t.updateRelationships(entryID, relationships, modified.GetRelationships()); err != nil {
-		err = fmt.Errorf("error updating relationships: %v", err)
+		return keyBkt.ForEach(func(lookupID, _ []byte) (err error) {
+			ls = append(ls, action.Lookup{Key: string(lookupKey), ID: string(lookupID)})
+			return
+		})
+	})
+
+	return
+}
+
+func (t *Transaction[T]) isManualLookup(lookupKey, lookupID, entryID []byte) (ok bool, err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getManualLookupsBucket(entryID, false); err != nil || bkt == nil {
 		return
 	}
 
-	if err = t.insertEntry(entryID, modified); err != nil {
-		return
+	if keyBkt := bkt.GetBucket(lookupKey); keyBkt != nil {
+		ok = len(keyBkt.Get(lookupID)) > 0
 	}
 
-	updated = modified
 	return
 }
 
-func (t *Transaction[T]) update(entryID []byte, fn UpdateFn[T]) (updated T, err error) {
-	return t.edit(entryID, func(in T) (out T, err error) {
-		if err = fn(in); err != nil {
-			return
-		}
-
-		out = in
+// setManualLookup will set a lookup for an entry and track it as a manual lookup of the
+// entry, so it's removed with the entry and retained by reindexes
+func (t *Transaction[T]) setManualLookup(lookupKey, lookupID, entryID []byte) (err error) {
+	if err = t.trackManualLookup(lookupKey, lookupID, entryID); err != nil {
 		return
-	}, false)
-}
+	}
 
-func (t *Transaction[T]) put(entryID []byte, val T) (updated T, err error) {
-	return t.edit(entryID, func(_ T) (out T, err error) { return val, nil }, true)
+	aw := t.newActionWriter(time.Now())
+	return aw.SetLookup(entryID, action.Lookup{Key: string(lookupKey), ID: string(lookupID)})
 }
 
-func (t *Transaction[T]) delete(entryID []byte) (deleted T, err error) {
-	if err = t.cc.isDone(); err != nil {
+// trackManualLookup will set a lookup for an entry along with it's manual lookup record
+func (t *Transaction[T]) trackManualLookup(lookupKey, lookupID, entryID []byte) (err error) {
+	if err = t.setLookup(lookupKey, lookupID, entryID); err != nil {
 		return
 	}
 
-	var val T
-	if val, err = t.get(entryID); err != nil {
-		err = fmt.Errorf("error finding entry <%s>: %v", entryID, err)
+	var bkt backend.Bucket
+	if bkt, err = t.getManualLookupsBucket(entryID, true); err != nil {
 		return
 	}
 
-	if err = t.deleteEntry(entryID); err != nil {
-		err = fmt.Errorf("error removing entry <%s>: %v", entryID, err)
+	var keyBkt backend.Bucket
+	if keyBkt, err = bkt.GetOrCreateBucket(lookupKey); err != nil {
 		return
 	}
 
-	if err = t.unsetRelationships(val.GetRelationships(), entryID); err != nil {
-		err = fmt.Errorf("error unsetting relationships: %v", err)
+	return keyBkt.Put(lookupID, lookupID)
+}
+
+// removeManualLookup will remove a lookup, along with it's manual lookup record
+func (t *Transaction[T]) removeManualLookup(lookupKey, lookupID []byte) (err error) {
+	var entryID []byte
+	switch entryID, err = t.getLookup(lookupKey, lookupID); err {
+	case nil:
+	case ErrLookupNotFound:
+		return nil
+
+	default:
 		return
 	}
 
-	aw := action.MakeWriter(t.bw)
-	if err = aw.Delete(entryID); err != nil {
+	// The entry ID is copied, as it's only valid until the lookup is removed
+	entryID = bytes.Clone(entryID)
+	if err = t.deleteManualLookup(lookupKey, lookupID, entryID); err != nil {
 		return
 	}
 
-	deleted = val
-	return
-}
-
-func (t *Transaction[T]) loadMeta() (err error) {
-	var bkt backend.Bucket
-	if bkt, err = t.getMetaBucket(); err != nil {
+	if err = t.removeLookup(lookupKey, lookupID); err != nil {
 		return
 	}
 
-	var bs []byte
-	if bs = bkt.Get([]byte("value")); len(bs) == 0 {
+	aw := t.newActionWriter(time.Now())
+	return aw.RemoveLookup(entryID, action.Lookup{Key: string(lookupKey), ID: string(lookupID)})
+}
+
+func (t *Transaction[T]) deleteManualLookup(lookupKey, lookupID, entryID []byte) (err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getManualLookupsBucket(entryID, false); err != nil || bkt == nil {
 		return
 	}
 
-	var meta metadata
-	if err = json.Unmarshal(bs, &meta); err != nil {
+	var keyBkt backend.Bucket
+	if keyBkt = bkt.GetBucket(lookupKey); keyBkt == nil {
 		return
 	}
 
-	t.meta = meta
-	return
+	return keyBkt.Delete(lookupID)
 }
 
-func (t *Transaction[T]) saveMeta() (err error) {
-	if !t.metaUpdated {
+// unsetManualLookups will remove the manual lookups of an entry
+func (t *Transaction[T]) unsetManualLookups(entryID []byte) (err error) {
+	var ls []action.Lookup
+	if ls, err = t.getManualLookups(entryID); err != nil || len(ls) == 0 {
 		return
 	}
 
-	var bkt backend.Bucket
-	if bkt, err = t.getMetaBucket(); err != nil {
-		return
+	var manualBkt backend.Bucket
+	if manualBkt = t.txn.GetBucket(manualLookupsBktKey); manualBkt == nil {
+		return ErrNotInitialized
 	}
 
-	var bs []byte
-	if bs, err = json.Marshal(t.meta); err != nil {
+	if err = manualBkt.DeleteBucket(entryID); err != nil {
 		return
 	}
 
-	return bkt.Put([]byte("value"), bs)
-}
+	for _, l := range ls {
+		if err = t.unsetLookup([]byte(l.Key), []byte(l.ID), entryID); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// setAllManualLookups will set the lookups of every manual lookup record
+func (t *Transaction[T]) setAllManualLookups() (err error) {
+	var manualBkt backend.Bucket
+	if manualBkt = t.txn.GetBucket(manualLookupsBktKey); manualBkt == nil {
+		return ErrNotInitialized
+	}
+
+	return manualBkt.ForEach(func(entryID, _ []byte) (err error) {
+		var ls []action.Lookup
+		if ls, err = t.getManualLookups(entryID); err != nil {
+			return
+		}
+
+		for _, l := range ls {
+			if err = t.setLookup([]byte(l.Key), []byte(l.ID), entryID); err != nil {
+				err = fmt.Errorf("error setting lookup <%s> <%s> for <%s>: %v", l.Key, l.ID, entryID, err)
+				return
+			}
+		}
+
+		return
+	})
+}
+
+// copyPosition will write the position of the last change as a comment. Snapshot blocks are not
+// changes, so mirrors which sync from a snapshot track the position they have caught up to with it
+func (t *Transaction[T]) copyPosition(bw action.BlockWriter) (err error) {
+	if err = t.ensureMeta(); err != nil {
+		return
+	}
+
+	aw := action.MakeWriter(bw)
+	aw.SetMetadata(action.Metadata{Position: t.meta.Position})
+	return aw.Comment(nil)
+}
+
+// copyManualLookups will write the manual lookups, so mirrors are able to rebuild them from a snapshot
+func (t *Transaction[T]) copyManualLookups(bw action.BlockWriter) (err error) {
+	var manualBkt backend.Bucket
+	if manualBkt = t.txn.GetBucket(manualLookupsBktKey); manualBkt == nil {
+		return ErrNotInitialized
+	}
+
+	aw := action.MakeWriter(bw)
+	return manualBkt.ForEach(func(entryID, _ []byte) (err error) {
+		var ls []action.Lookup
+		if ls, err = t.getManualLookups(entryID); err != nil {
+			return
+		}
+
+		for _, l := range ls {
+			if err = aw.SetLookup(entryID, l); err != nil {
+				return
+			}
+		}
+
+		return
+	})
+}
+
+func (t *Transaction[T]) getCountsBucket() (bkt backend.Bucket, err error) {
+	if bkt = t.txn.GetBucket(countsBktKey); bkt == nil {
+		err = ErrNotInitialized
+		return
+	}
+
+	return
+}
+
+func (t *Transaction[T]) getRelationshipCount(relationship, relationshipID []byte) (n int64, err error) {
+	// Ensure the relationship exists
+	if _, err = t.getRelationshipBucket(relationship); err != nil {
+		return
+	}
+
+	var countsBkt backend.Bucket
+	if countsBkt, err = t.getCountsBucket(); err != nil {
+		return
+	}
+
+	var bkt backend.Bucket
+	if bkt = countsBkt.GetBucket(relationship); bkt == nil {
+		return
+	}
+
+	n = decodeCount(bkt.Get(relationshipID))
+	return
+}
+
+func (t *Transaction[T]) updateRelationshipCount(relationship, relationshipID []byte, delta int64) (err error) {
+	var countsBkt backend.Bucket
+	if countsBkt, err = t.getCountsBucket(); err != nil {
+		return
+	}
+
+	var bkt backend.Bucket
+	if bkt, err = countsBkt.GetOrCreateBucket(relationship); err != nil {
+		return
+	}
+
+	n := decodeCount(bkt.Get(relationshipID)) + delta
+	if n <= 0 {
+		// No more entries exist for this relationship ID, remove the count
+		return bkt.Delete(relationshipID)
+	}
+
+	return bkt.Put(relationshipID, encodeCount(n))
+}
+
+func (t *Transaction[T]) getEntryCount() (n int64, err error) {
+	if err = t.ensureMeta(); err != nil {
+		return
+	}
+
+	n = t.meta.EntryCount
+	return
+}
+
+func (t *Transaction[T]) addEntryCount(delta int64) {
+	t.meta.EntryCount += delta
+	t.metaUpdated = true
+}
+
+// indexCounts will rebuild the entry count and relationship counts
+func (t *Transaction[T]) indexCounts() (err error) {
+	var entriesBkt backend.Bucket
+	if entriesBkt, err = t.getEntriesBucket(); err != nil {
+		return
+	}
+
+	if err = t.txn.DeleteBucket(countsBktKey); err != nil {
+		return
+	}
+
+	if _, err = t.txn.GetOrCreateBucket(countsBktKey); err != nil {
+		return
+	}
+
+	for _, relationship := range t.m.relationships {
+		var parentBkt backend.Bucket
+		if parentBkt, err = t.getRelationshipBucket(relationship); err != nil {
+			return
+		}
+
+		cur := parentBkt.Cursor()
+		for relationshipID, _ := cur.First(); relationshipID != nil; relationshipID, _ = cur.Next() {
+			bkt := parentBkt.GetBucket(relationshipID)
+			if bkt == nil {
+				continue
+			}
+
+			n := countKeys(bkt.Cursor(), nil, nil, -1)
+			if err = t.updateRelationshipCount(relationship, bytes.Clone(relationshipID), n); err != nil {
+				return
+			}
+		}
+	}
+
+	t.meta.EntryCount = countKeys(entriesBkt.Cursor(), nil, nil, -1)
+	t.meta.CountsIndexed = true
+	t.metaUpdated = true
+	return
+}
+
+func (t *Transaction[T]) count(o *FilteringOpts) (n int64, err error) {
+	if o == nil {
+		o = defaultFilteringOpts
+	}
+
+	if len(o.LastID) == 0 {
+		switch {
+		case len(o.Filters) == 0:
+			return t.getEntryCount()
+		case len(o.Filters) == 1:
+			if f, ok := o.Filters[0].(*filters.MatchFilter); ok {
+				return t.getRelationshipCount([]byte(f.RelationshipKey), []byte(f.RelationshipID))
+			}
+		}
+	}
+
+	err = t.ForEachID(func(_ string) (err error) {
+		n++
+		return
+	}, o)
+	return
+}
+
+func (t *Transaction[T]) updateRelationships(entryID []byte, orig, new Relationships) (err error) {
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	for i, relationship := range new {
+		onAdd := func(relationshipID []byte) (err error) {
+			return t.setRelationship(t.m.relationships[i], relationshipID, entryID)
+		}
+
+		onRemove := func(relationshipID []byte) (err error) {
+			return t.unsetRelationship(t.m.relationships[i], relationshipID, entryID)
+		}
+
+		var origR Relationship
+		if orig != nil {
+			origR = orig[i]
+		}
+
+		if err = relationship.delta(origR, onAdd, onRemove); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (t *Transaction[T]) checkUniqueRelationships(entryID []byte, orig, new Relationships) (err error) {
+	for i, relationship := range new {
+		if i >= len(t.m.uniqueRelationships) || !t.m.uniqueRelationships[i] {
+			continue
+		}
+
+		onAdd := func(relationshipID []byte) (err error) {
+			return t.checkUniqueRelationship(t.m.relationships[i], relationshipID, entryID)
+		}
+
+		var origR Relationship
+		if orig != nil {
+			origR = orig[i]
+		}
+
+		if err = relationship.addNew(origR, onAdd); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (t *Transaction[T]) checkUniqueRelationship(relationship, relationshipID, entryID []byte) (err error) {
+	if len(relationshipID) == 0 {
+		// Unset relationship IDs can be ignored
+		return
+	}
+
+	var relationshipBkt backend.Bucket
+	if relationshipBkt, err = t.getRelationshipBucket(relationship); err != nil {
+		err = fmt.Errorf("error getting relationship bucket <%s>: %v", relationship, err)
+		return
+	}
+
+	var bkt backend.Bucket
+	if bkt = relationshipBkt.GetBucket(relationshipID); bkt == nil {
+		return
+	}
+
+	cur := bkt.Cursor()
+	for key, _ := cur.First(); key != nil; key, _ = cur.Next() {
+		if bytes.Equal(key, entryID) {
+			continue
+		}
+
+		return &UniqueConstraintError{
+			RelationshipKey: string(relationship),
+			RelationshipID:  string(relationshipID),
+			EntryID:         string(key),
+		}
+	}
+
+	return
+}
+
+// getLast will attempt to get the first entry which matches the provided filters
+// Note: Will return ErrEntryNotFound if no match is found
+func (t *Transaction[T]) getFirst(o *FilteringOpts) (value T, err error) {
+	var cur IDCursor
+	if cur, err = t.idCursor(o.Filters); err != nil {
+		return
+	}
+
+	var entryID string
+	if entryID, err = getFirstID(cur, o.LastID, false); err == Break {
+		err = ErrEntryNotFound
+		return
+	} else if err != nil {
+		return
+	}
+
+	return t.get([]byte(entryID))
+}
+
+// getLast will attempt to get the last entry which matches the provided filters
+// Note: Will return ErrEntryNotFound if no match is found
+func (t *Transaction[T]) getLast(o *FilteringOpts) (value T, err error) {
+	var cur IDCursor
+	if cur, err = t.idCursor(o.Filters); err != nil {
+		return
+	}
+
+	var entryID string
+	if entryID, err = getFirstID(cur, o.LastID, true); err == Break {
+		err = ErrEntryNotFound
+		return
+	} else if err != nil {
+		return
+	}
+
+	return t.get([]byte(entryID))
+}
+
+func (t *Transaction[T]) getFiltered(o *FilteringOpts) (es []T, lastID string, err error) {
+	return t.appendFiltered(nil, o)
+}
+
+func (t *Transaction[T]) getFilteredIDs(o *FilteringOpts) (IDs []string, lastID string, err error) {
+	return t.appendFilteredIDs(nil, o)
+}
+
+func (t *Transaction[T]) appendFiltered(in []T, o *FilteringOpts) (out []T, lastID string, err error) {
+	if o == nil {
+		o = defaultFilteringOpts
+	}
+
+	if o.Limit == 0 {
+		return
+	}
+
+	var c Cursor[T]
+	if c, err = t.cursor(o.Filters); err != nil {
+		return
+	}
+
+	var count int64
+	out = in
+	err = t.forEachWithCursor(c, o, func(entryID string, val T) (err error) {
+		out = append(out, val)
+		if count++; count == o.Limit {
+			lastID = joinSeekID(c.getCurrentRelationshipID(), entryID)
+			return Break
+		}
+
+		return
+	})
+
+	return
+}
+
+func (t *Transaction[T]) appendFilteredIDs(in []string, o *FilteringOpts) (out []string, lastID string, err error) {
+	if o == nil {
+		o = defaultFilteringOpts
+	}
+
+	if o.Limit == 0 {
+		return
+	}
+
+	var c Cursor[T]
+	if c, err = t.cursor(o.Filters); err != nil {
+		return
+	}
+
+	var count int64
+	out = in
+	err = t.forEachWithCursor(c, o, func(entryID string, _ T) (err error) {
+		out = append(out, entryID)
+		if count++; count == o.Limit {
+			lastID = joinSeekID(c.getCurrentRelationshipID(), entryID)
+			return Break
+		}
+
+		return
+	})
+
+	return
+}
+
+func (t *Transaction[T]) forEachWithCursor(c Cursor[T], o *FilteringOpts, fn ForEachFn[T]) (err error) {
+	var val T
+	iterator := getIteratorFunc(c, o.Reverse)
+	val, err = getFirst(c, o.LastID, o.Reverse)
+	for err == nil {
+		if err = fn(val.GetID(), val); err != nil {
+			break
+		}
+
+		val, err = iterator()
+	}
+
+	if err == Break {
+		err = nil
+	}
+
+	return
+}
+
+func (t *Transaction[T]) new(val T) (created T, err error) {
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	index := t.meta.CurrentIndex
+	t.setIndex(index + 1)
+
+	var entryID string
+	if entryID, err = t.getIDGenerator().NewID(index); err != nil {
+		return
+	}
+
+	return t.put([]byte(entryID), val)
+}
+
+func (t *Transaction[T]) newMany(vals []T) (created []T, err error) {
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	// Allocate the index range for all of the values up front
+	start := t.meta.CurrentIndex
+	t.setIndex(start + uint64(len(vals)))
+
+	created = make([]T, 0, len(vals))
+	for i, val := range vals {
+		var entryID string
+		if entryID, err = t.getIDGenerator().NewID(start + uint64(i)); err != nil {
+			return
+		}
+
+		var c T
+		if c, err = t.put([]byte(entryID), val); err != nil {
+			return
+		}
+
+		created = append(created, c)
+	}
+
+	return
+}
+
+func (t *Transaction[T]) putMany(vals map[string]T) (updated map[string]T, err error) {
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	// Write entries in key order so the history is deterministic
+	entryIDs := slices.Sorted(maps.Keys(vals))
+	updated = make(map[string]T, len(vals))
+	for _, entryID := range entryIDs {
+		var u T
+		if u, err = t.put([]byte(entryID), vals[entryID]); err != nil {
+			return
+		}
+
+		updated[entryID] = u
+	}
+
+	return
+}
+
+func (t *Transaction[T]) edit(entryID []byte, fn editFn[T], allowInsert bool) (updated T, err error) {
+	return t.write(entryID, fn, allowInsert, writeModeEdit)
+}
+
+// replicate will write a value as-is, retaining it's timestamps and version
+func (t *Transaction[T]) replicate(entryID []byte, val T) (updated T, err error) {
+	return t.write(entryID, func(_ T) (out T, err error) { return val, nil }, true, writeModeReplicate)
+}
+
+// importEntry will write an imported value, retaining the version set by the primary
+func (t *Transaction[T]) importEntry(entryID []byte, val T) (updated T, err error) {
+	return t.write(entryID, func(_ T) (out T, err error) { return val, nil }, true, writeModeImport)
+}
+
+func (t *Transaction[T]) write(entryID []byte, fn editFn[T], allowInsert bool, mode writeMode) (updated T, err error) {
+	if len(entryID) == 0 {
+		err = ErrEmptyEntryID
+		return
+	}
+
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	var (
+		orig          T
+		relationships Relationships
+		lookups       Lookups
+		version       int64
+	)
+
+	orig, err = t.get(entryID)
+	switch {
+	case err == nil:
+		var indexed T
+		if indexed, err = t.getIndexed(entryID, orig); err != nil {
+			return
+		}
+
+		relationships = t.m.getRelationships(indexed)
+		lookups = getLookups(indexed)
+		version = getVersion(orig)
+	case err == ErrEntryNotFound && allowInsert:
+	default:
+		return
+	}
+
+	var modified T
+	if modified, err = fn(orig); err != nil {
+		return
+	}
+
+	switch mode {
+	case writeModeReplicate:
+		modified.SetID(string(entryID))
+	case writeModeImport:
+		// Versions must agree with the primary, so replays do not increment the version
+		setEssetialValues(entryID, modified)
+	case writeModeRestore:
+		// Restored entries continue from the version they were deleted with
+		setEssetialValues(entryID, modified)
+		setVersion(modified, getVersion(modified)+1)
+
+	default:
+		setEssetialValues(entryID, modified)
+		setVersion(modified, version+1)
+	}
+
+	newRelationships := t.m.getRelationships(modified)
+	// Ensure unique relationships are not held by other entries before anything is written
+	if err = t.checkUniqueRelationships(entryID, relationships, newRelationships); err != nil {
+		return
+	}
+
+	// Update lookups (if needed)
+	if err = t.updateLookups(entryID, lookups, getLookups(modified)); err != nil {
+		return
+	}
+
+	// Update relationships (if needed)
+	if err = t.updateRelationships(entryID, relationships, newRelationships); err != nil {
+		err = fmt.Errorf("error updating relationships: %v", err)
+		return
+	}
+
+	if err = t.insertEntry(entryID, modified); err != nil {
+		return
+	}
+
+	updated = modified
+	return
+}
+
+// getMatchingIDs will collect the matching entry IDs ahead of any mutations, as writing to
+// buckets during iteration would invalidate the underlying cursors
+func (t *Transaction[T]) getMatchingIDs(o *FilteringOpts) (entryIDs []string, err error) {
+	err = t.ForEachID(func(entryID string) (err error) {
+		entryIDs = append(entryIDs, entryID)
+		return
+	}, o)
+	return
+}
+
+func (t *Transaction[T]) deleteFiltered(o *FilteringOpts) (n int64, err error) {
+	var entryIDs []string
+	if entryIDs, err = t.getMatchingIDs(o); err != nil {
+		return
+	}
+
+	for _, entryID := range entryIDs {
+		if _, err = t.delete([]byte(entryID)); err != nil {
+			return
+		}
+
+		n++
+	}
+
+	return
+}
+
+func (t *Transaction[T]) updateFiltered(o *FilteringOpts, fn UpdateFn[T]) (n int64, err error) {
+	var entryIDs []string
+	if entryIDs, err = t.getMatchingIDs(o); err != nil {
+		return
+	}
+
+	for _, entryID := range entryIDs {
+		_, err = t.update([]byte(entryID), fn)
+		switch {
+		case err == Break:
+			err = nil
+			return
+		case err != nil:
+			return
+		}
+
+		n++
+	}
+
+	return
+}
+
+func (t *Transaction[T]) create(entryID []byte, val T) (created T, err error) {
+	var exists bool
+	if exists, err = t.exists(entryID); err != nil {
+		return
+	}
+
+	if exists {
+		err = ErrEntryExists
+		return
+	}
+
+	return t.put(entryID, val)
+}
+
+func (t *Transaction[T]) upsert(entryID []byte, fn UpsertFn[T]) (updated T, err error) {
+	var found bool
+	if found, err = t.exists(entryID); err != nil {
+		return
+	}
+
+	return t.edit(entryID, func(in T) (out T, err error) {
+		return fn(in, found)
+	}, true)
+}
+
+func (t *Transaction[T]) update(entryID []byte, fn UpdateFn[T]) (updated T, err error) {
+	return t.edit(entryID, func(in T) (out T, err error) {
+		if err = fn(in); err != nil {
+			return
+		}
+
+		out = in
+		return
+	}, false)
+}
+
+func (t *Transaction[T]) put(entryID []byte, val T) (updated T, err error) {
+	return t.edit(entryID, func(_ T) (out T, err error) { return val, nil }, true)
+}
+
+func (t *Transaction[T]) getVersion(entryID []byte) (version int64, exists bool, err error) {
+	if !isVersioned[T]() {
+		err = ErrVersionsNotSupported
+		return
+	}
+
+	var val T
+	val, err = t.get(entryID)
+	switch {
+	case err == nil:
+		version = getVersion(val)
+		exists = true
+	case err == ErrEntryNotFound:
+		err = nil
+	}
+
+	return
+}
+
+func (t *Transaction[T]) putIfVersion(entryID []byte, expectedVersion int64, val T) (updated T, err error) {
+	var (
+		version int64
+		exists  bool
+	)
+
+	if version, exists, err = t.getVersion(entryID); err != nil {
+		return
+	}
+
+	// Existence is checked separately, as entries written before versions were introduced
+	// are stored with a version of 0
+	if exists != (expectedVersion != 0) || version != expectedVersion {
+		err = ErrVersionConflict
+		return
+	}
+
+	return t.put(entryID, val)
+}
+
+func (t *Transaction[T]) deleteIfVersion(entryID []byte, expectedVersion int64) (deleted T, err error) {
+	if !isVersioned[T]() {
+		err = ErrVersionsNotSupported
+		return
+	}
+
+	var val T
+	if val, err = t.get(entryID); err != nil {
+		return
+	}
+
+	// An expected version of 0 only matches entries which do not exist
+	if expectedVersion == 0 || getVersion(val) != expectedVersion {
+		err = ErrVersionConflict
+		return
+	}
+
+	return t.delete(entryID)
+}
+
+func (t *Transaction[T]) delete(entryID []byte) (deleted T, err error) {
+	if err = t.cc.isDone(); err != nil {
+		return
+	}
+
+	var val T
+	if val, err = t.get(entryID); err != nil {
+		err = fmt.Errorf("error finding entry <%s>: %v", entryID, err)
+		return
+	}
+
+	var indexed T
+	if indexed, err = t.getIndexed(entryID, val); err != nil {
+		return
+	}
+
+	if err = t.deleteEntry(entryID); err != nil {
+		err = fmt.Errorf("error removing entry <%s>: %v", entryID, err)
+		return
+	}
+
+	if err = t.unsetRelationships(t.m.getRelationships(indexed), entryID); err != nil {
+		err = fmt.Errorf("error unsetting relationships: %v", err)
+		return
+	}
+
+	var manualLookups []action.Lookup
+	// Manual lookups are retained within the tombstone, so they are set once again when restored
+	if t.m.opts.SoftDelete {
+		if manualLookups, err = t.getManualLookups(entryID); err != nil {
+			return
+		}
+	}
+
+	if err = t.unsetManualLookups(entryID); err != nil {
+		err = fmt.Errorf("error unsetting manual lookups: %v", err)
+		return
+	}
+
+	if err = t.unsetLookups(getLookups(indexed), entryID); err != nil {
+		err = fmt.Errorf("error unsetting lookups: %v", err)
+		return
+	}
+
+	var bs []byte
+	if bs, err = t.m.marshal(val); err != nil {
+		return
+	}
+
+	deletedAt := t.getTimestamp()
+	if t.m.opts.SoftDelete {
+		if err = t.putTombstone(entryID, t.newTombstone(bs, deletedAt, manualLookups)); err != nil {
+			err = fmt.Errorf("error creating tombstone for <%s>: %v", entryID, err)
+			return
+		}
+	}
+
+	position := t.addChange(action.TypeDelete, entryID, bs, nil)
+	md := t.newChangeMetadata(deletedAt, position)
+	if err = t.indexRevision(action.TypeDelete, entryID, md); err != nil {
+		return
+	}
+
+	// The deleted value is included, so subscriptions resuming from the history and revisions receive it
+	aw := t.newChangeWriter(md)
+	if err = aw.DeleteValue(entryID, bs); err != nil {
+		return
+	}
+
+	deleted = val
+	return
+}
+
+func (t *Transaction[T]) loadMeta() (err error) {
+	var bkt backend.Bucket
+	if bkt, err = t.getMetaBucket(); err != nil {
+		return
+	}
+
+	var bs []byte
+	if bs = bkt.Get([]byte("value")); len(bs) == 0 {
+		return
+	}
+
+	var meta metadata
+	if err = json.Unmarshal(bs, &meta); err != nil {
+		return
+	}
+
+	t.meta = meta
+	t.metaLoaded = true
+	return
+}
+
+// ensureMeta will load the meta if it has not been loaded yet
+// Note: Meta is only loaded up front for write transactions
+func (t *Transaction[T]) ensureMeta() (err error) {
+	if t.metaLoaded {
+		return
+	}
+
+	return t.loadMeta()
+}
+
+func (t *Transaction[T]) saveMeta() (err error) {
+	if !t.metaUpdated {
+		return
+	}
+
+	var bkt backend.Bucket
+	if bkt, err = t.getMetaBucket(); err != nil {
+		return
+	}
+
+	var bs []byte
+	if bs, err = json.Marshal(t.meta); err != nil {
+		return
+	}
+
+	return bkt.Put([]byte("value"), bs)
+}
 
 func (t *Transaction[T]) setIndex(index uint64) {
 	t.meta.CurrentIndex = index
 	t.metaUpdated = true
 }
 
+// addChange will add a change to be published once the transaction has been committed and
+// return the position of the change
+// Note: Imported changes are not published, as they were published by the primary
+func (t *Transaction[T]) addChange(actionType action.Type, entryID, bs, previous []byte) (position uint64) {
+	if t.bw == nil || t.bw == nopBW {
+		return
+	}
+
+	t.meta.Position++
+	t.metaUpdated = true
+
+	var c Change[T]
+	c.Position = t.meta.Position
+	c.Type = actionType
+	c.EntryID = string(entryID)
+	// The encoded values are retained, they are decoded once the transaction has been committed
+	c.value = bs
+	c.previous = previous
+	t.changes = append(t.changes, c)
+	return c.Position
+}
+
+// getChangePrevious will return a copy of the current value of an entry for a change, upgraded
+// to the current schema version so it's decoded like the value of the change
+func (t *Transaction[T]) getChangePrevious(entryID, current []byte) (previous []byte, err error) {
+	if len(current) == 0 || t.bw == nil || t.bw == nopBW {
+		// Entry does not exist, or changes are not being published
+		return
+	}
+
+	if previous, err = t.upgrade(entryID, current); err != nil {
+		return
+	}
+
+	previous = append([]byte(nil), previous...)
+	return
+}
+
+// trackPosition will advance the position to the last change of an imported action, so a
+// promoted mirror continues from the positions of the primary
+func (t *Transaction[T]) trackPosition(a *action.Action) {
+	position := a.Metadata.Position
+	if position > 0 && a.Type == action.TypeMove {
+		// Moves are a delete followed by a write
+		position++
+	}
+
+	if position <= t.meta.Position {
+		return
+	}
+
+	t.meta.Position = position
+	t.metaUpdated = true
+}
+
+// newActionWriter will return an action writer which includes the transaction metadata,
+// stamped with the provided wall-clock time
+func (t *Transaction[T]) newActionWriter(timestamp time.Time) (aw action.Writer) {
+	md := t.actionMetadata
+	md.Timestamp = timestamp
+	aw = action.MakeWriter(t.bw)
+	aw.SetMetadata(md)
+	return
+}
+
+// newChangeMetadata will return the metadata of a change, which includes the position of the
+// change so subscriptions are able to resume from the history. Imported changes retain the
+// metadata of the imported block
+func (t *Transaction[T]) newChangeMetadata(timestamp time.Time, position uint64) (md action.Metadata) {
+	if t.bw == nopBW {
+		return t.blockMetadata
+	}
+
+	md = t.actionMetadata
+	md.Timestamp = timestamp
+	md.Position = position
+	return
+}
+
+// newChangeWriter will return an action writer for the block of a change
+func (t *Transaction[T]) newChangeWriter(md action.Metadata) (aw action.Writer) {
+	aw = action.MakeWriter(t.bw)
+	aw.SetMetadata(md)
+	return
+}
+
 func (t *Transaction[T]) processBlock(b kiroku.Block) (err error) {
 	var a action.Action
 	if err = enkodo.NewReader(bytes.NewReader(b)).Decode(&a); err != nil {
 		return
 	}
 
+	t.blockMetadata = a.Metadata
+	defer func() { t.blockMetadata = action.Metadata{} }()
+	t.trackPosition(&a)
+	if a.Type == action.TypeComment {
+		// Comments are not applied to entries
+		return
+	}
+
+	var (
+		imported ImportedEntry[T]
+		indexed  Relationships
+	)
+
+	if t.importEvent != nil {
+		if imported, indexed, err = t.getImportedEntry(a.Type, a.Key); err != nil {
+			err = fmt.Errorf("processBlock(): error getting previous entry <%s>: %v", string(a.Key), err)
+			return
+		}
+	}
+
 	switch a.Type {
 	case action.TypeWrite:
 		var val T
@@ -571,23 +1579,112 @@ func (t *Transaction[T]) processBlock(b kiroku.Block) (err error) {
 			return
 		}
 
-		var idx uint64
-		if idx, err = parseIDAsIndex(a.Key); err == nil && idx >= t.meta.CurrentIndex {
+		if idx, ok := parseIndex(t.getIDGenerator(), a.Key); ok && idx >= t.meta.CurrentIndex {
 			t.setIndex(idx + 1)
 		}
 
-		if _, err = t.put(a.Key, val); err != nil {
+		t.trackIndexLength(a.Key)
+
+		if _, err = t.importEntry(a.Key, val); err != nil {
 			err = fmt.Errorf("processBlock(): error putting entry <%s>: %v", string(a.Key), err)
 			return
 		}
 
+		if t.importEvent != nil {
+			t.addImportedEntry(imported, indexed, val)
+		}
+
 		return
 	case action.TypeDelete:
-		if err = t.deleteEntry(a.Key); err != nil {
+		var exists bool
+		if exists, err = t.exists(a.Key); err != nil {
+			return
+		}
+
+		if !exists {
+			// Entry does not exist, the delete can only create a tombstone
+			if err = t.importTombstone(&a); err != nil {
+				err = fmt.Errorf("processBlock(): error importing tombstone <%s>: %v", string(a.Key), err)
+			}
+
+			return
+		}
+
+		// Deletes are fully applied so the relationships, lookups and counts are unset
+		if _, err = t.delete(a.Key); err != nil {
 			err = fmt.Errorf("processBlock(): error deleting entry <%s>: %v", string(a.Key), err)
 			return
 		}
 
+		if t.importEvent != nil {
+			t.addImportedEntry(imported, indexed, imported.Previous)
+		}
+
+		return
+	case action.TypeMove:
+		var mv action.Move
+		if mv, err = a.Move(); err != nil {
+			err = fmt.Errorf("processBlock(): error decoding move for <%s>: %v", string(a.Key), err)
+			return
+		}
+
+		var moved T
+		if moved, err = t.move(a.Key, []byte(mv.EntryID)); err != nil {
+			err = fmt.Errorf("processBlock(): error moving <%s> to <%s>: %v", string(a.Key), mv.EntryID, err)
+			return
+		}
+
+		if t.importEvent != nil && imported.HasPrevious {
+			// Moves are presented as a delete of the previous entry and a write of the new entry
+			imported.Type = action.TypeDelete
+			t.addImportedEntry(imported, indexed, imported.Previous)
+			t.addImportedEntry(ImportedEntry[T]{Type: action.TypeWrite, EntryID: mv.EntryID}, nil, moved)
+		}
+
+		return
+	case action.TypePurge:
+		if err = t.deleteTombstone(a.Key); err != nil {
+			err = fmt.Errorf("processBlock(): error purging tombstone <%s>: %v", string(a.Key), err)
+		}
+
+		return
+	case action.TypeSetLookup:
+		var l action.Lookup
+		if l, err = a.Lookup(); err != nil {
+			err = fmt.Errorf("processBlock(): error decoding lookup for <%s>: %v", string(a.Key), err)
+			return
+		}
+
+		var exists bool
+		if exists, err = t.exists(a.Key); err != nil {
+			return
+		}
+
+		if !exists {
+			// Entry does not exist, the lookup can only belong to a tombstone
+			if err = t.importTombstoneLookup(a.Key, l); err != nil {
+				err = fmt.Errorf("processBlock(): error importing lookup <%s> <%s> for tombstone <%s>: %v", l.Key, l.ID, string(a.Key), err)
+			}
+
+			return
+		}
+
+		if err = t.setManualLookup([]byte(l.Key), []byte(l.ID), a.Key); err != nil {
+			err = fmt.Errorf("processBlock(): error setting lookup <%s> <%s> for <%s>: %v", l.Key, l.ID, string(a.Key), err)
+		}
+
+		return
+	case action.TypeRemoveLookup:
+		var l action.Lookup
+		if l, err = a.Lookup(); err != nil {
+			err = fmt.Errorf("processBlock(): error decoding lookup for <%s>: %v", string(a.Key), err)
+			return
+		}
+
+		if err = t.removeManualLookup([]byte(l.Key), []byte(l.ID)); err != nil {
+			err = fmt.Errorf("processBlock(): error removing lookup <%s> <%s>: %v", l.Key, l.ID, err)
+		}
+
 		return
 	}
 
@@ -604,6 +1701,31 @@ func (t *Transaction[T]) New(val T) (created T, err error) {
 	return t.new(val)
 }
 
+// SetActor will set the actor ID written with the remaining actions of the transaction
+func (t *Transaction[T]) SetActor(actorID string) {
+	t.actionMetadata.ActorID = actorID
+}
+
+// SetRequestID will set the request ID written with the remaining actions of the transaction
+func (t *Transaction[T]) SetRequestID(requestID string) {
+	t.actionMetadata.RequestID = requestID
+}
+
+// SetReason will set the reason written with the remaining actions of the transaction
+func (t *Transaction[T]) SetReason(reason string) {
+	t.actionMetadata.Reason = reason
+}
+
+// Restore will restore a soft-deleted entry
+func (t *Transaction[T]) Restore(entryID string) (restored T, err error) {
+	return t.restore([]byte(entryID))
+}
+
+// Purge will permanently remove the soft-deleted entries which were deleted before olderThan
+func (t *Transaction[T]) Purge(olderThan time.Time) (n int64, err error) {
+	return t.purge(olderThan)
+}
+
 // Exists will notiy if an entry exists for a given entry ID
 func (t *Transaction[T]) Exists(entryID string) (exists bool, err error) {
 	return t.exists([]byte(entryID))
@@ -646,6 +1768,23 @@ func (t *Transaction[T]) GetLast(o *FilteringOpts) (val T, err error) {
 	return t.getLast(o)
 }
 
+// Explain will return the plan which would be used for the provided filtering options
+func (t *Transaction[T]) Explain(o *FilteringOpts) (p Plan, err error) {
+	return t.explain(o)
+}
+
+// Count will return the number of entries which match the provided filtering options
+// Note: Limit is ignored
+func (t *Transaction[T]) Count(o *FilteringOpts) (n int64, err error) {
+	return t.count(o)
+}
+
+// GetByLookup will attempt to get an entry by lookup
+// Note: Will return ErrLookupNotFound if the lookup does not exist
+func (t *Transaction[T]) GetByLookup(lookupKey, lookupID string) (val T, err error) {
+	return t.getByLookup([]byte(lookupKey), []byte(lookupID))
+}
+
 // IDCursor will return an ID iterating cursor
 func (t *Transaction[T]) IDCursor(fs ...Filter) (c IDCursor, err error) {
 	return t.idCursor(fs)
@@ -683,6 +1822,22 @@ func (t *Transaction[T]) ForEach(fn ForEachFn[T], o *FilteringOpts) (err error)
 	return
 }
 
+// All will return an iterator of the entry IDs and entries which match the filtering options
+// Note: Iteration errors are set to errp (when not nil) once the iteration has ended
+func (t *Transaction[T]) All(o *FilteringOpts, errp *error) iter.Seq2[string, T] {
+	return makeSeq2(errp, func(yield func(string, T) bool) error {
+		return t.iterate(o, yield)
+	})
+}
+
+// IDs will return an iterator of the entry IDs which match the filtering options
+// Note: Iteration errors are set to errp (when not nil) once the iteration has ended
+func (t *Transaction[T]) IDs(o *FilteringOpts, errp *error) iter.Seq[string] {
+	return makeSeq(errp, func(yield func(string) bool) error {
+		return t.iterateIDs(o, yield)
+	})
+}
+
 // ForEachID will iterate through entry IDs
 func (t *Transaction[T]) ForEachID(fn ForEachIDFn, o *FilteringOpts) (err error) {
 	if o == nil {
@@ -726,5 +1881,79 @@ func (t *Transaction[T]) Delete(entryID string) (deleted T, err error) {
 	return t.delete([]byte(entryID))
 }
 
+// DeleteFiltered will remove all of the entries which match the filtering options and
+// return the number of entries deleted
+// Note: Limit is ignored
+func (t *Transaction[T]) DeleteFiltered(o *FilteringOpts) (n int64, err error) {
+	return t.deleteFiltered(o)
+}
+
+// UpdateFiltered will update all of the entries which match the filtering options and
+// return the number of entries updated. Returning Break from the update func will end the
+// updates early, leaving the current entry unchanged
+// Note: Limit is ignored
+func (t *Transaction[T]) UpdateFiltered(o *FilteringOpts, fn UpdateFn[T]) (n int64, err error) {
+	return t.updateFiltered(o, fn)
+}
+
+// NewMany will insert new entries with the given values and return the created
+// entries in the same order as the provided values
+func (t *Transaction[T]) NewMany(vals []T) (created []T, err error) {
+	return t.newMany(vals)
+}
+
+// PutMany will place entries at the given entry IDs
+// Note: This will not check to see if the entries exist beforehand
+func (t *Transaction[T]) PutMany(vals map[string]T) (updated map[string]T, err error) {
+	return t.putMany(vals)
+}
+
+// Create will place an entry at a given entry ID if an entry does not already exist
+// Note: Will return ErrEntryExists if an entry exists for the given entry ID
+func (t *Transaction[T]) Create(entryID string, val T) (created T, err error) {
+	return t.create([]byte(entryID), val)
+}
+
+// Upsert will update an existing entry or insert a new entry at a given entry ID
+func (t *Transaction[T]) Upsert(entryID string, fn UpsertFn[T]) (updated T, err error) {
+	return t.upsert([]byte(entryID), fn)
+}
+
+// PutIfVersion will place an entry at a given entry ID if the current version matches the
+// expected version. An expected version of 0 will only match entries which do not exist
+// Note: Entries written before versions were introduced have a version of 0 and must be
+// written once (e.g. with Put) before their version can be matched
+// Note: Will return ErrVersionConflict if the versions do not match
+func (t *Transaction[T]) PutIfVersion(entryID string, expectedVersion int64, val T) (updated T, err error) {
+	return t.putIfVersion([]byte(entryID), expectedVersion, val)
+}
+
+// DeleteIfVersion will remove an entry if the current version matches the expected version
+// Note: Will return ErrVersionConflict if the versions do not match
+func (t *Transaction[T]) DeleteIfVersion(entryID string, expectedVersion int64) (deleted T, err error) {
+	return t.deleteIfVersion([]byte(entryID), expectedVersion)
+}
+
+// SetLookup will set a unique lookup for an entry
+// Note: Will return ErrLookupExists if the lookup is set for another entry. The lookup is
+// removed when the entry is deleted
+func (t *Transaction[T]) SetLookup(lookupKey, lookupID, entryID string) (err error) {
+	var exists bool
+	if exists, err = t.exists([]byte(entryID)); err != nil {
+		return
+	}
+
+	if !exists {
+		return ErrEntryNotFound
+	}
+
+	return t.setManualLookup([]byte(lookupKey), []byte(lookupID), []byte(entryID))
+}
+
+// RemoveLookup will remove a unique lookup
+func (t *Transaction[T]) RemoveLookup(lookupKey, lookupID string) (err error) {
+	return t.removeManualLookup([]byte(lookupKey), []byte(lookupID))
+}
+
 // TransactionFn represents a transaction function
 type TransactionFn[T Value] func(*Transaction[T]) error
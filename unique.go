@@ -0,0 +1,49 @@
+package mojura
+
+import (
+	"fmt"
+	"strings"
+)
+
+// uniqueSuffix is appended to relationship keys which only allow a single entry per relationship ID
+const uniqueSuffix = ",unique"
+
+// Unique will mark a relationship key as unique, meaning at most one entry may hold a given
+// relationship ID. It is intended to be used when providing relationships to New:
+//
+//	New[*User](opts, "groups", mojura.Unique("emails"))
+func Unique(relationshipKey string) string {
+	return relationshipKey + uniqueSuffix
+}
+
+// UniqueConstraintError is returned when a write would result in a unique relationship ID being
+// held by more than one entry
+// Note: UniqueConstraintError will match ErrUniqueConstraintViolation when using errors.Is
+type UniqueConstraintError struct {
+	RelationshipKey string `json:"relationshipKey"`
+	RelationshipID  string `json:"relationshipID"`
+	// EntryID of the entry which currently holds the relationship ID
+	EntryID string `json:"entryID"`
+}
+
+// Error will return the error message
+func (u *UniqueConstraintError) Error() string {
+	return fmt.Sprintf("%v: relationship <%s> with ID <%s> is held by <%s>", ErrUniqueConstraintViolation, u.RelationshipKey, u.RelationshipID, u.EntryID)
+}
+
+// Unwrap will return ErrUniqueConstraintViolation
+func (u *UniqueConstraintError) Unwrap() error {
+	return ErrUniqueConstraintViolation
+}
+
+func parseRelationships(in []string) (relationships []string, unique []bool) {
+	relationships = make([]string, 0, len(in))
+	unique = make([]bool, 0, len(in))
+	for _, relationship := range in {
+		key, isUnique := strings.CutSuffix(relationship, uniqueSuffix)
+		relationships = append(relationships, key)
+		unique = append(unique, isUnique)
+	}
+
+	return
+}
@@ -3,6 +3,7 @@ package mojura
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"strconv"
 	"strings"
@@ -139,6 +140,34 @@ func hasEntries(bkt backend.Bucket) (ok bool) {
 	return len(k) > 0
 }
 
+func toFilters(in []interface{}) (fs []Filter) {
+	fs = make([]Filter, 0, len(in))
+	for _, f := range in {
+		fs = append(fs, f)
+	}
+
+	return
+}
+
+func hasKey(bkt backend.Bucket, key []byte) (ok bool) {
+	k, _ := bkt.Cursor().Seek(key)
+	return bytes.Equal(k, key)
+}
+
+func encodeCount(n int64) (bs []byte) {
+	bs = make([]byte, 8)
+	binary.BigEndian.PutUint64(bs, uint64(n))
+	return
+}
+
+func decodeCount(bs []byte) (n int64) {
+	if len(bs) != 8 {
+		return
+	}
+
+	return int64(binary.BigEndian.Uint64(bs))
+}
+
 func getRelationshipsAsBytes(relationships []string) (out [][]byte) {
 	for _, relationship := range relationships {
 		rbs := []byte(relationship)
@@ -162,6 +191,10 @@ func closeSema(c chan struct{}) {
 
 type UpdateFn[T Value] func(T) error
 
+// UpsertFn is called during an upsert. Found notes if the existing value was found, when
+// false the existing value will be the zero value of T
+type UpsertFn[T Value] func(existing T, found bool) (T, error)
+
 type editFn[T Value] func(T) (T, error)
 
 func setEssetialValues[T Value](entryID []byte, t T) {
@@ -0,0 +1,47 @@
+package mojura
+
+// Versioner is an optional interface for Values which track versions. Versions are
+// incremented on each write and are used for compare-and-swap writes
+// Note: Entry implements Versioner
+type Versioner interface {
+	GetVersion() int64
+	SetVersion(int64)
+}
+
+// writeMode determines which of the essential values are set by a write
+type writeMode uint8
+
+const (
+	// writeModeEdit sets the ID and timestamps, and increments the version
+	writeModeEdit writeMode = iota
+	// writeModeImport sets the ID and timestamps, retaining the version of the value
+	writeModeImport
+	// writeModeReplicate sets the ID, retaining the timestamps and version of the value
+	writeModeReplicate
+	// writeModeRestore sets the ID and timestamps, and increments the version of the value
+	writeModeRestore
+)
+
+func isVersioned[T Value]() (ok bool) {
+	var val T
+	_, ok = any(val).(Versioner)
+	return
+}
+
+func getVersion[T Value](val T) (version int64) {
+	versioner, ok := any(val).(Versioner)
+	if !ok {
+		return
+	}
+
+	return versioner.GetVersion()
+}
+
+func setVersion[T Value](val T, version int64) {
+	versioner, ok := any(val).(Versioner)
+	if !ok {
+		return
+	}
+
+	versioner.SetVersion(version)
+}
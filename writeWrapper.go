@@ -1,5 +1,10 @@
 package mojura
 
+import (
+	"context"
+	"time"
+)
+
 func MakeWriteWrapper[T Value](m *Mojura[T]) (w WriteWrapper[T]) {
 	w.m = m
 	return
@@ -14,6 +19,11 @@ func (w *WriteWrapper[T]) New(val T) (created T, err error) {
 	return w.m.New(val)
 }
 
+// NewCtx will insert a new entry with the given value and the associated relationships
+func (w *WriteWrapper[T]) NewCtx(ctx context.Context, val T) (created T, err error) {
+	return w.m.NewCtx(ctx, val)
+}
+
 // Put will place an entry at a given entry ID
 // Note: This will not check to see if the entry exists beforehand. If this functionality
 // is needed, look into using the Edit method
@@ -21,12 +31,124 @@ func (w *WriteWrapper[T]) Put(entryID string, val T) (updated T, err error) {
 	return w.m.Put(entryID, val)
 }
 
+// PutCtx will place an entry at a given entry ID
+// Note: This will not check to see if the entry exists beforehand. If this functionality
+// is needed, look into using the Edit method
+func (w *WriteWrapper[T]) PutCtx(ctx context.Context, entryID string, val T) (updated T, err error) {
+	return w.m.PutCtx(ctx, entryID, val)
+}
+
 // Edit will attempt to edit an entry by ID
 func (w *WriteWrapper[T]) Update(entryID string, fn UpdateFn[T]) (updated T, err error) {
 	return w.m.Update(entryID, fn)
 }
 
+// Edit will attempt to edit an entry by ID
+func (w *WriteWrapper[T]) UpdateCtx(ctx context.Context, entryID string, fn UpdateFn[T]) (updated T, err error) {
+	return w.m.UpdateCtx(ctx, entryID, fn)
+}
+
 // Delete will remove an entry and it's related relationship IDs
 func (w *WriteWrapper[T]) Delete(entryID string) (deleted T, err error) {
 	return w.m.Delete(entryID)
 }
+
+// DeleteCtx will remove an entry and it's related relationship IDs
+func (w *WriteWrapper[T]) DeleteCtx(ctx context.Context, entryID string) (deleted T, err error) {
+	return w.m.DeleteCtx(ctx, entryID)
+}
+
+// Restore will restore a soft-deleted entry, along with it's relationships and lookups
+func (w *WriteWrapper[T]) Restore(entryID string) (restored T, err error) {
+	return w.m.Restore(entryID)
+}
+
+// RestoreCtx will restore a soft-deleted entry, along with it's relationships and lookups
+func (w *WriteWrapper[T]) RestoreCtx(ctx context.Context, entryID string) (restored T, err error) {
+	return w.m.RestoreCtx(ctx, entryID)
+}
+
+// Purge will permanently remove the soft-deleted entries which were deleted before olderThan
+func (w *WriteWrapper[T]) Purge(olderThan time.Time) (n int64, err error) {
+	return w.m.Purge(olderThan)
+}
+
+// NewMany will insert new entries with the given values
+func (w *WriteWrapper[T]) NewMany(ctx context.Context, vals []T) (created []T, err error) {
+	return w.m.NewMany(ctx, vals)
+}
+
+// PutMany will place entries at the given entry IDs
+func (w *WriteWrapper[T]) PutMany(ctx context.Context, vals map[string]T) (updated map[string]T, err error) {
+	return w.m.PutMany(ctx, vals)
+}
+
+// DeleteFiltered will remove all of the entries which match the filtering options
+func (w *WriteWrapper[T]) DeleteFiltered(ctx context.Context, o *FilteringOpts) (n int64, err error) {
+	return w.m.DeleteFiltered(ctx, o)
+}
+
+// UpdateFiltered will update all of the entries which match the filtering options
+func (w *WriteWrapper[T]) UpdateFiltered(ctx context.Context, o *FilteringOpts, fn UpdateFn[T]) (n int64, err error) {
+	return w.m.UpdateFiltered(ctx, o, fn)
+}
+
+// Create will place an entry at a given entry ID if an entry does not already exist
+func (w *WriteWrapper[T]) Create(entryID string, val T) (created T, err error) {
+	return w.m.Create(entryID, val)
+}
+
+// CreateCtx will place an entry at a given entry ID if an entry does not already exist
+func (w *WriteWrapper[T]) CreateCtx(ctx context.Context, entryID string, val T) (created T, err error) {
+	return w.m.CreateCtx(ctx, entryID, val)
+}
+
+// Upsert will update an existing entry or insert a new entry at a given entry ID
+func (w *WriteWrapper[T]) Upsert(entryID string, fn UpsertFn[T]) (updated T, err error) {
+	return w.m.Upsert(entryID, fn)
+}
+
+// UpsertCtx will update an existing entry or insert a new entry at a given entry ID
+func (w *WriteWrapper[T]) UpsertCtx(ctx context.Context, entryID string, fn UpsertFn[T]) (updated T, err error) {
+	return w.m.UpsertCtx(ctx, entryID, fn)
+}
+
+// PutIfVersion will place an entry at a given entry ID if the current version matches the expected version
+func (w *WriteWrapper[T]) PutIfVersion(entryID string, expectedVersion int64, val T) (updated T, err error) {
+	return w.m.PutIfVersion(entryID, expectedVersion, val)
+}
+
+// PutIfVersionCtx will place an entry at a given entry ID if the current version matches the expected version
+func (w *WriteWrapper[T]) PutIfVersionCtx(ctx context.Context, entryID string, expectedVersion int64, val T) (updated T, err error) {
+	return w.m.PutIfVersionCtx(ctx, entryID, expectedVersion, val)
+}
+
+// DeleteIfVersion will remove an entry if the current version matches the expected version
+func (w *WriteWrapper[T]) DeleteIfVersion(entryID string, expectedVersion int64) (deleted T, err error) {
+	return w.m.DeleteIfVersion(entryID, expectedVersion)
+}
+
+// DeleteIfVersionCtx will remove an entry if the current version matches the expected version
+func (w *WriteWrapper[T]) DeleteIfVersionCtx(ctx context.Context, entryID string, expectedVersion int64) (deleted T, err error) {
+	return w.m.DeleteIfVersionCtx(ctx, entryID, expectedVersion)
+}
+
+// SetLookup will set a unique lookup for an entry
+func (w *WriteWrapper[T]) SetLookup(lookupKey, lookupID, entryID string) (err error) {
+	return w.m.SetLookup(lookupKey, lookupID, entryID)
+}
+
+// SetLookupCtx will set a unique lookup for an entry
+func (w *WriteWrapper[T]) SetLookupCtx(ctx context.Context, lookupKey, lookupID, entryID string) (err error) {
+	return w.m.SetLookupCtx(ctx, lookupKey, lookupID, entryID)
+}
+
+// RemoveLookup will remove a unique lookup
+func (w *WriteWrapper[T]) RemoveLookup(lookupKey, lookupID string) (err error) {
+	return w.m.RemoveLookup(lookupKey, lookupID)
+}
+
+// RemoveLookupCtx will remove a unique lookup
+func (w *WriteWrapper[T]) RemoveLookupCtx(ctx context.Context, lookupKey, lookupID string) (err error) {
+	return w.m.RemoveLookupCtx(ctx, lookupKey, lookupID)
+}